@@ -5,13 +5,21 @@ skinparam {
 	arrowFontSize 10
 }
 
+{{- fixed := .Fixed }}
 {{ range .Schemas }}
   {{- range .Tables }}
 entity "{{ .Name }}{{ if .Title != "" }}\n<size:11>({{ .Title }})</size>{{ end }}" as {{ .Name }} {
   |= |= <size:11>name</size> |= <size:11>type</size> |
+    {{- table := . }}
+    {{- pk := .PrimaryKeyColumns(fixed) }}
     {{- range .Columns }}
-      {{- if .Identity == "Y" || .ForeignKey != "" }}
-  | {{ if .Identity == "Y" }}<size:11>PK</size>{{ end }}{{ if .ForeignKey != "" }}<size:11>FK</size>{{ end }} | <size:11>{{ .Name }}</size> | <size:11>{{ .DataType }}</size> |
+      {{- column := .Name }}
+      {{- isFK := .ForeignKey != "" }}
+      {{- range table.ForeignKeys }}
+        {{- if contains(.Columns, column) }}{{ isFK = true }}{{ end }}
+      {{- end }}
+      {{- if contains(pk, .Name) || isFK }}
+  | {{ if contains(pk, .Name) }}<size:11>PK</size>{{ end }}{{ if isFK }}<size:11>FK</size>{{ end }} | <size:11>{{ .Name }}</size> | <size:11>{{ .DataType }}</size> |
       {{- end }}
     {{- end }}
 }
@@ -27,6 +35,9 @@ entity "{{ .Name }}{{ if .Title != "" }}\n<size:11>({{ .Title }})</size>{{ end }
 entity {{ parts[0] }}
       {{- end }}
     {{- end }}
+    {{- range .ForeignKeys }}
+entity {{ .RefTable }}
+    {{- end }}
   {{- end }}
 {{- end }}
 
@@ -40,6 +51,15 @@ entity {{ parts[0] }}
 {{ table }} }o--{{ if .NotNull == "Y" }}||{{ end }}{{ if .NotNull != "Y" }}o|{{ end }} {{ parts[0] }} #text:FireBrick : "{{ .Name }}"
       {{- end }}
     {{- end }}
+    {{- columns := .Columns }}
+    {{- range .ForeignKeys }}
+      {{- fk := . }}
+      {{- optional := false }}
+      {{- range columns }}
+        {{- if contains(fk.Columns, .Name) && .NotNull != "Y" }}{{ optional = true }}{{ end }}
+      {{- end }}
+{{ table }} }o--{{ if optional }}o|{{ else }}||{{ end }} {{ .RefTable }} #text:FireBrick : "{{ join(.Columns, ", ") }}"
+    {{- end }}
   {{- end }}
 {{- end }}
 
@@ -1,24 +1,38 @@
 {* ---------------------------------- tables ---------------------------------- *}
+{{- data := . }}
 {{- fixed := .Fixed }}
 {{ range .Schemas }}
   {{- range .Tables }}
 CREATE TABLE IF NOT EXISTS {{ .Name }} (
+    {{- pk := .PrimaryKeyColumns(fixed) }}
+    {{- checks := data.Checks(.) }}
+    {{- more := len(pk) > 0 || len(checks) > 0 }}
+    {{- columnCount := len(.Columns) }}
+    {{- tableFixed := .FixedColumns(fixed) }}
+    {{- fixedCount := len(tableFixed) }}
     {{- range i := .Columns}}
-      {{ .Name }} {{ .DataType }}
+      {{ .Name }} {{ sqlType(., "mariadb") }}
       {{- if .NotNull == "Y" }} NOT NULL {{- end }}
       {{- if .Value != "" }} DEFAULT '{{ .Value }}' {{- end }}
-      {{- if .Identity == "Y" }} AUTO_INCREMENT PRIMARY KEY {{- end }}
-      {{- if .Desc != "" }} COMMENT '{{ .Desc }}' {{- end }}
-      {{- "," }}
+      {{- if .Identity == "Y" }} AUTO_INCREMENT {{- end }}
+      {{- if .Desc != "" }} COMMENT {{ sqlString(.Desc) }} {{- end }}
+      {{- if i < columnCount - 1 || fixedCount > 0 || more }},{{- end }}
     {{- end }}
-    {{- fixedCount := len(fixed) }}
-    {{- range i := fixed }}
-      {{ .Name }} {{ .DataType }}
+    {{- range i := tableFixed }}
+      {{ .Name }} {{ sqlType(., "mariadb") }}
       {{- if .NotNull == "Y" }} NOT NULL {{- end }}
       {{- if .Value != "" }} DEFAULT '{{ .Value }}' {{- end }}
-      {{- if .Identity == "Y" }} AUTO_INCREMENT PRIMARY KEY {{- end }}
-      {{- if .Desc != "" }} COMMENT '{{ .Desc }}' {{- end }}
-      {{- if i < fixedCount - 1 }},{{- end }}
+      {{- if .Identity == "Y" }} AUTO_INCREMENT {{- end }}
+      {{- if .Desc != "" }} COMMENT {{ sqlString(.Desc) }} {{- end }}
+      {{- if i < fixedCount - 1 || more }},{{- end }}
+    {{- end }}
+    {{- if len(pk) > 0 }}
+      PRIMARY KEY ({{ join(pk, ", ") }})
+      {{- if len(checks) > 0 }},{{- end }}
+    {{- end }}
+    {{- range i := checks }}
+      CONSTRAINT {{ .Name }} CHECK ({{ .Expr }})
+      {{- if i < len(checks) - 1 }},{{- end }}
     {{- end }}
 );
   {{- end }}
@@ -28,16 +42,27 @@ CREATE TABLE IF NOT EXISTS {{ .Name }} (
 {{ range .Schemas }}
   {{- range .Tables }}
     {{- table := .Name }}
-    {{- range .Columns }}
-      {{- parts := split(.ForeignKey, ".") }}
-      {{- if len(parts) > 1 }}
-ALTER TABLE IF EXISTS {{ table }} ADD CONSTRAINT fk_{{ table }}_{{ .Name }} FOREIGN KEY ({{ .Name }}) REFERENCES {{ parts[0] }} ({{ parts[1] }});
-      {{- end }}
+    {{- range data.ForeignKeys(.) }}
+ALTER TABLE IF EXISTS {{ table }} ADD CONSTRAINT {{ .Name != "" ? .Name : "fk_" + table + "_" + join(.Columns, "_") }} FOREIGN KEY ({{ join(.Columns, ", ") }}) REFERENCES {{ .RefTable }} ({{ join(.RefColumns, ", ") }})
+      {{- if .OnDelete != "" }} ON DELETE {{ upper(.OnDelete) }}{{ end }}
+      {{- if .OnUpdate != "" }} ON UPDATE {{ upper(.OnUpdate) }}{{ end }};
     {{- end }}
-    {{- range fixed }}
-      {{- parts := split(.ForeignKey, ".") }}
-      {{- if len(parts) > 1 }}
-ALTER TABLE IF EXISTS {{ table }} ADD CONSTRAINT fk_{{ table }}_{{ .Name }} FOREIGN KEY ({{ .Name }}) REFERENCES {{ parts[0] }} ({{ parts[1] }});
+  {{- end }}
+{{- end }}
+
+{* --------------------------------- indexes -------------------------------- *}
+{{ range .Schemas }}
+  {{- range .Tables }}
+    {{- table := .Name }}
+    {{- range .Indexes }}
+      {{- ix := .Name != "" ? .Name : "idx_" + table + "_" + join(.ColumnNames(), "_") }}
+      {{- if .Where != "" }}
+-- {{ ix }} skipped, partial index is not supported: WHERE {{ .Where }}
+      {{- else }}
+CREATE {{ if .Unique }}UNIQUE {{ end }}INDEX IF NOT EXISTS {{ ix }} ON {{ table }} ({{ join(.Columns, ", ") }});
+        {{- if len(.Include) > 0 }}
+-- {{ ix }} included columns skipped, INCLUDE is not supported: {{ join(.Include, ", ") }}
+        {{- end }}
       {{- end }}
     {{- end }}
   {{- end }}
@@ -49,7 +74,7 @@ ALTER TABLE IF EXISTS {{ table }} ADD CONSTRAINT fk_{{ table }}_{{ .Name }} FORE
     {{- table := .Name }}
     {{- lastColumn := "" }}
     {{- range i := .Columns }}
-ALTER TABLE {{ table }} ADD COLUMN IF NOT EXISTS {{ .Name }} {{ .DataType }}
+ALTER TABLE {{ table }} ADD COLUMN IF NOT EXISTS {{ .Name }} {{ sqlType(., "mariadb") }}
       {{- if i != 0 }} AFTER {{ lastColumn }}{{ end }}
       {{- ";" }}
       {{- lastColumn = .Name }}
@@ -61,27 +86,15 @@ ALTER TABLE {{ table }} ADD COLUMN IF NOT EXISTS {{ .Name }} {{ .DataType }}
 {{ range .Schemas }}
   {{- range .Tables }}
     {{- table := .Name }}
-    {{- range .Columns }}
-      {{- parts := split(.ForeignKey, ".") }}
-      {{- if len(parts) > 1 }}
-ALTER TABLE IF EXISTS {{ table }} DROP CONSTRAINT IF EXISTS fk_{{ table }}_{{ .Name }};
-      {{- end }}
-    {{- end }}
-    {{- range fixed }}
-      {{- parts := split(.ForeignKey, ".") }}
-      {{- if len(parts) > 1 }}
-ALTER TABLE IF EXISTS {{ table }} DROP CONSTRAINT fk_{{ table }}_{{ .Name }};
-      {{- end }}
+    {{- range data.ForeignKeys(.) }}
+ALTER TABLE IF EXISTS {{ table }} DROP CONSTRAINT IF EXISTS {{ .Name != "" ? .Name : "fk_" + table + "_" + join(.Columns, "_") }};
     {{- end }}
   {{- end }}
 {{- end }}
 
-{* -------------------------------- drop table -------------------------------- *}
-{{ range .Schemas }}
-  {{- range .Tables }}
-DROP TABLE IF EXISTS {{ .Name }};
-    {{- table := .Name }}
-  {{- end }}
+{* -------------- drop table, the referencing tables are dropped first -------------- *}
+{{ range reverseTables(sortTables(.)) }}
+DROP TABLE IF EXISTS {{ .Table.Name }};
 {{- end }}
 
 
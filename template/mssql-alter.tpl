@@ -1,10 +1,11 @@
 -- +goose Up
+{{- data := . }}
 {* -- ------------------------------ ADD COLUMNS ------------------------------- -- *}
 {{- range .Schemas }}
   {{- range .Tables }}
     {{- table := .Name }}
     {{- range .Columns}}
-IF COL_LENGTH(N'{{ table }}', N'{{ .Name }}') IS NULL ALTER TABLE {{ table }} ADD {{ .Name }} {{ .DataType }} {{- if .NotNull == "Y" }} NOT NULL {{- end }};
+IF COL_LENGTH(N'{{ table }}', N'{{ .Name }}') IS NULL ALTER TABLE {{ table }} ADD {{ .Name }} {{ sqlType(., "mssql") }} {{- if .NotNull == "Y" }} NOT NULL {{- end }};
     {{- end }}
   {{- end }}
 {{- end }}
@@ -12,27 +13,65 @@ IF COL_LENGTH(N'{{ table }}', N'{{ .Name }}') IS NULL ALTER TABLE {{ table }} AD
 {{- range .Schemas }}
   {{- range .Tables }}
     {{- table := .Name }}
-    {{- range .Columns}}
-      {{- parts := split(.ForeignKey, ".") }}
-      {{- if len(parts) > 1 }}
-IF OBJECT_ID(N'fk{{ table }}{{ .Name }}', N'F') IS NULL ALTER TABLE {{ table }} ADD CONSTRAINT fk{{ table }}{{ .Name }} FOREIGN KEY ({{ .Name }}) REFERENCES {{ parts[0] }} ({{ parts[1] }});
+    {{- range data.ForeignKeys(.) }}
+      {{- fk := .Name != "" ? .Name : "fk" + table + join(.Columns, "") }}
+IF OBJECT_ID(N'{{ fk }}', N'F') IS NULL ALTER TABLE {{ table }} ADD CONSTRAINT {{ fk }} FOREIGN KEY ({{ join(.Columns, ", ") }}) REFERENCES {{ .RefTable }} ({{ join(.RefColumns, ", ") }})
+      {{- if .OnDelete != "" }} ON DELETE {{ upper(.OnDelete) }}{{ end }}
+      {{- if .OnUpdate != "" }} ON UPDATE {{ upper(.OnUpdate) }}{{ end }};
+    {{- end }}
+  {{- end }}
+{{- end }}
 
-      {{- end }}
+{* ---------------------------- check constraints ----------------------------- *}
+{{- range .Schemas }}
+  {{- range .Tables }}
+    {{- table := .Name }}
+    {{- range data.Checks(.) }}
+IF OBJECT_ID(N'{{ .Name }}', N'C') IS NULL ALTER TABLE {{ table }} ADD CONSTRAINT {{ .Name }} CHECK ({{ .Expr }});
+    {{- end }}
+  {{- end }}
+{{- end }}
+{* -- ------------------------------ CREATE INDEX ------------------------------- -- *}
+{{- range .Schemas }}
+  {{- range .Tables }}
+    {{- table := .Name }}
+    {{- range .Indexes }}
+      {{- ix := .Name != "" ? .Name : "idx" + table + join(.ColumnNames(), "") }}
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = '{{ ix }}' AND object_id = OBJECT_ID('{{ table }}')) CREATE{{ if .Unique }} UNIQUE{{ end }} INDEX {{ ix }} ON {{ table }} ({{ join(.Columns, ", ") }})
+      {{- if len(.Include) > 0 }} INCLUDE ({{ join(.Include, ", ") }}){{ end }}
+      {{- if .Where != "" }} WHERE {{ .Where }}{{ end }};
     {{- end }}
   {{- end }}
 {{- end }}
 
 
 -- +goose Down
+{* ---------------------------- check constraints ----------------------------- *}
+{{- range .Schemas }}
+  {{- range .Tables }}
+    {{- table := .Name }}
+    {{- range data.Checks(.) }}
+IF OBJECT_ID(N'{{ .Name }}', N'C') IS NOT NULL ALTER TABLE {{ table }} DROP CONSTRAINT {{ .Name }};
+    {{- end }}
+  {{- end }}
+{{- end }}
 {* ------------------------------- foreign keys ------------------------------- *}
 {{- range .Schemas }}
   {{- range .Tables }}
     {{- table := .Name }}
-    {{- range .Columns}}
-      {{- parts := split(.ForeignKey, ".") }}
-      {{- if len(parts) > 1 }}
-IF OBJECT_ID(N'fk{{ table }}{{ .Name }}', N'F') IS NOT NULL ALTER TABLE {{ table }} DROP CONSTRAINT IF EXISTS fk{{ table }}{{ .Name }};
-      {{- end }}
+    {{- range data.ForeignKeys(.) }}
+      {{- fk := .Name != "" ? .Name : "fk" + table + join(.Columns, "") }}
+IF OBJECT_ID(N'{{ fk }}', N'F') IS NOT NULL ALTER TABLE {{ table }} DROP CONSTRAINT IF EXISTS {{ fk }};
+    {{- end }}
+  {{- end }}
+{{- end }}
+{* -- ------------------------------- DROP INDEX ------------------------------- -- *}
+{{- range .Schemas }}
+  {{- range .Tables }}
+    {{- table := .Name }}
+    {{- range .Indexes }}
+      {{- ix := .Name != "" ? .Name : "idx" + table + join(.ColumnNames(), "") }}
+DROP INDEX IF EXISTS {{ ix }} ON {{ table }};
     {{- end }}
   {{- end }}
 {{- end }}
@@ -1,32 +1,35 @@
 -- +goose Up
 -- +goose NO TRANSACTION
 {* ---------------------------------- tables ---------------------------------- *}
+{{- data := . }}
 {{- fixed := .Fixed }}
 {{- range .Schemas }}
   {{- range .Tables }}
 IF OBJECT_ID(N'{{ .Name }}', N'U') IS NULL
 BEGIN
 CREATE TABLE {{ .Name }} (
-    {{- idc := "" }}
+    {{- pk := .PrimaryKeyColumns(fixed) }}
+    {{- tableFixed := .FixedColumns(fixed) }}
+    {{- columnCount := len(.Columns) }}
+    {{- fixedCount := len(tableFixed) }}
     {{- range i := .Columns}}
-      {{- if .Identity == "Y" }}
-        {{- idc = . }}
-      {{- end }}
-      {{ .Name }} {{ .DataType }}
+      {{ .Name }} {{ sqlType(., "mssql") }}
       {{- if .Identity == "Y" }} IDENTITY(1,1){{- end }}
       {{- if .Value != "" }} DEFAULT '{{ .Value }}' {{- end }}
       {{- if .NotNull == "Y" }} NOT NULL {{- end }}
-      {{- "," }}
+      {{- if i < columnCount - 1 || fixedCount > 0 }},{{- end }}
     {{- end }}
-    {{- fixedCount := len(fixed) }}
-    {{- range i := fixed }}
-      {{ .Name }} {{ .DataType }}
+    {{- range i := tableFixed }}
+      {{ .Name }} {{ sqlType(., "mssql") }}
       {{- if .Value != "" }} DEFAULT '{{ .Value }}' {{- end }}
       {{- if .NotNull == "Y" }} NOT NULL {{- end }}
       {{- if i < fixedCount - 1 }},{{- end }}
     {{- end }}
-    {{- if idc != "" }},
-      CONSTRAINT pk{{ .Name }}{{ idc.Name }} PRIMARY KEY ({{ idc.Name }})
+    {{- if len(pk) > 0 }},
+      CONSTRAINT pk{{ .Name }}{{ join(pk, "") }} PRIMARY KEY ({{ join(pk, ", ") }})
+    {{- end }}
+    {{- range data.Checks(.) }},
+      CONSTRAINT {{ .Name }} CHECK ({{ .Expr }})
     {{- end }}
 )
 END;
@@ -36,18 +39,11 @@ END;
 {{- range .Schemas }}
   {{- range .Tables }}
     {{- table := .Name }}
-    {{- range .Columns}}
-      {{- parts := split(.ForeignKey, ".") }}
-      {{- if len(parts) > 1 }}
-IF OBJECT_ID(N'fk{{ table }}{{ .Name }}', N'F') IS NULL ALTER TABLE {{ table }} ADD CONSTRAINT fk{{ table }}{{ .Name }} FOREIGN KEY ({{ .Name }}) REFERENCES {{ parts[0] }} ({{ parts[1] }});
-
-      {{- end }}
-    {{- end }}
-    {{- range fixed }}
-      {{- parts := split(.ForeignKey, ".") }}
-      {{- if len(parts) > 1 }}
-IF OBJECT_ID(N'fk{{ table }}{{ .Name }}', N'F') IS NULL ALTER TABLE {{ table }} ADD CONSTRAINT fk{{ table }}{{ .Name }} FOREIGN KEY ({{ .Name }}) REFERENCES {{ parts[0] }} ({{ parts[1] }});
-      {{- end }}
+    {{- range data.ForeignKeys(.) }}
+      {{- fk := .Name != "" ? .Name : "fk" + table + join(.Columns, "") }}
+IF OBJECT_ID(N'{{ fk }}', N'F') IS NULL ALTER TABLE {{ table }} ADD CONSTRAINT {{ fk }} FOREIGN KEY ({{ join(.Columns, ", ") }}) REFERENCES {{ .RefTable }} ({{ join(.RefColumns, ", ") }})
+      {{- if .OnDelete != "" }} ON DELETE {{ upper(.OnDelete) }}{{ end }}
+      {{- if .OnUpdate != "" }} ON UPDATE {{ upper(.OnUpdate) }}{{ end }};
     {{- end }}
   {{- end }}
 {{- end }}
@@ -65,6 +61,12 @@ IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'idx{{ table }}{{ .Name }}
 IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'idx{{ table }}{{ .Name }}' AND object_id = OBJECT_ID('{{ table }}')) CREATE{{ if .Unique == "Y" }} UNIQUE{{ end }} INDEX idx{{ table }}{{ .Name }} ON {{ table }} ({{ .Name }});
       {{- end }}
     {{- end }}
+    {{- range .Indexes }}
+      {{- ix := .Name != "" ? .Name : "idx" + table + join(.ColumnNames(), "") }}
+IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = '{{ ix }}' AND object_id = OBJECT_ID('{{ table }}')) CREATE{{ if .Unique }} UNIQUE{{ end }} INDEX {{ ix }} ON {{ table }} ({{ join(.Columns, ", ") }})
+      {{- if len(.Include) > 0 }} INCLUDE ({{ join(.Include, ", ") }}){{ end }}
+      {{- if .Where != "" }} WHERE {{ .Where }}{{ end }};
+    {{- end }}
   {{- end }}
 {{- end }}
 
@@ -74,26 +76,16 @@ IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = 'idx{{ table }}{{ .Name }}
 {{- range .Schemas }}
   {{- range .Tables }}
     {{- table := .Name }}
-    {{- range .Columns}}
-      {{- parts := split(.ForeignKey, ".") }}
-      {{- if len(parts) > 1 }}
-IF OBJECT_ID(N'fk{{ table }}{{ .Name }}', N'F') IS NOT NULL ALTER TABLE {{ table }} DROP CONSTRAINT IF EXISTS fk{{ table }}{{ .Name }};
-      {{- end }}
-    {{- end }}
-    {{- range fixed }}
-      {{- parts := split(.ForeignKey, ".") }}
-      {{- if len(parts) > 1 }}
-IF OBJECT_ID(N'fk{{ table }}{{ .Name }}', N'F') IS NOT NULL ALTER TABLE {{ table }} DROP CONSTRAINT IF EXISTS fk{{ table }}{{ .Name }};
-      {{- end }}
+    {{- range data.ForeignKeys(.) }}
+      {{- fk := .Name != "" ? .Name : "fk" + table + join(.Columns, "") }}
+IF OBJECT_ID(N'{{ fk }}', N'F') IS NOT NULL ALTER TABLE {{ table }} DROP CONSTRAINT IF EXISTS {{ fk }};
     {{- end }}
   {{- end }}
 {{- end }}
-{* ---------------------------------- tables ---------------------------------- *}
+{* -------------- tables, the referencing tables are dropped first -------------- *}
 
-{{- range .Schemas }}
-  {{- range .Tables }}
-DROP TABLE IF EXISTS {{ .Name }};
-  {{- end }}
+{{- range reverseTables(sortTables(.)) }}
+DROP TABLE IF EXISTS {{ .Table.Name }};
 {{- end }}
 
 
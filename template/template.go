@@ -0,0 +1,9 @@
+// Package template contains the built-in templates compiled into the binary.
+package template
+
+import "embed"
+
+// FS is the file system of the built-in templates (*.tpl).
+//
+//go:embed *.tpl
+var FS embed.FS
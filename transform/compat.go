@@ -0,0 +1,274 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+)
+
+// the compatibility classes of the changes for the running application, in
+// the order of the severity
+const (
+	CompatAdditive    = "additive"         // new object, e.g. nullable column
+	CompatWidening    = "widening"         // relaxed, e.g. longer string, nullable
+	CompatNarrowing   = "narrowing"        // restricted, e.g. shorter string, not null
+	CompatRename      = "rename-suspected" // dropped and added object of the same definition
+	CompatDestructive = "destructive"      // dropped table or column
+)
+
+// CompatClasses are the compatibility classes in the order of the severity.
+var CompatClasses = []string{CompatAdditive, CompatWidening, CompatNarrowing, CompatRename, CompatDestructive}
+
+// breakingClasses are the classes breaking the running application.
+var breakingClasses = []string{CompatNarrowing, CompatRename, CompatDestructive}
+
+// CompatPolicy is the policy of the compatibility check.
+type CompatPolicy struct {
+	// Forbid are the forbidden classes, the breaking classes (narrowing,
+	// rename-suspected and destructive) if empty
+	Forbid []string `yaml:"forbid,flow,omitempty"`
+}
+
+// Forbidden returns the forbidden classes of the policy, it returns an error
+// if any class is unknown.
+func (p CompatPolicy) Forbidden() ([]string, error) {
+	if len(p.Forbid) == 0 {
+		return breakingClasses, nil
+	}
+	forbid := lo.Map(p.Forbid, func(class string, _ int) string { return strings.ToLower(strings.TrimSpace(class)) })
+	if unknown, _ := lo.Difference(forbid, CompatClasses); len(unknown) > 0 {
+		return nil, tracerr.Errorf("unknown compatibility class(es) %s, supported classes: %s",
+			strings.Join(unknown, ", "), strings.Join(CompatClasses, ", "))
+	}
+	return forbid, nil
+}
+
+// typeRanks are the ranks of the logical types in the families, a type can be
+// widened to the type of the higher rank in the same family.
+var typeRanks = map[string][2]int{
+	"int16": {1, 1}, "int32": {1, 2}, "int64": {1, 3}, "decimal": {1, 4},
+	"float32": {2, 1}, "float64": {2, 2},
+	"char": {3, 1}, "string": {3, 2}, "text": {3, 3},
+	"bytes": {4, 1},
+}
+
+// rawCompatTypes are the logical types of the common raw types, so the raw
+// types are compared by typeRanks as well, e.g. VARCHAR(50) to VARCHAR(100).
+var rawCompatTypes = map[string]string{
+	"CHAR": "char", "NCHAR": "char", "CHARACTER": "char",
+	"VARCHAR": "string", "NVARCHAR": "string", "CHARACTER VARYING": "string",
+	"TEXT": "text", "NTEXT": "text", "MEDIUMTEXT": "text", "LONGTEXT": "text",
+	"SMALLINT": "int16", "INT": "int32", "INTEGER": "int32", "BIGINT": "int64",
+	"DECIMAL": "decimal", "NUMERIC": "decimal",
+	"REAL": "float32", "FLOAT": "float64", "DOUBLE": "float64", "DOUBLE PRECISION": "float64",
+}
+
+// compatType returns the logical type of the data type to compare, the common
+// raw types are converted by rawCompatTypes (the length MAX is unlimited), it
+// returns false if the type cannot be compared.
+func compatType(dataType string) (Type, bool) {
+	t, err := ParseType(dataType)
+	if err != nil || t.Logical {
+		return t, err == nil
+	}
+	m := typeRegexp.FindStringSubmatch(dataType)
+	name, found := rawCompatTypes[strings.ToUpper(t.Name)]
+	if m == nil || !found {
+		return t, false
+	}
+	if params := strings.TrimSpace(m[2]); params != "" && !strings.EqualFold(params, "MAX") {
+		name += "(" + params + ")"
+	}
+	t, err = ParseType(name)
+	return t, err == nil
+}
+
+// typeWidened returns true if the new type can hold all values of the old
+// type, e.g. string(50) to string(100), int32 to int64 or VARCHAR(50) to
+// VARCHAR(100). Other raw types are widened only if they are the same.
+func typeWidened(oldType string, newType string) bool {
+	o, ocompat := compatType(oldType)
+	n, ncompat := compatType(newType)
+	if !ocompat || !ncompat {
+		return typeKey(oldType) == typeKey(newType)
+	}
+	orank, nrank := typeRanks[o.Name], typeRanks[n.Name]
+	if orank[0] != nrank[0] || orank[1] > nrank[1] {
+		return false
+	}
+	if o.Name != n.Name && n.Name == "decimal" {
+		// the decimal must be unlimited or large enough for the integer
+		return n.Precision == 0 || n.Precision-n.Scale >= 19
+	}
+	// no length or precision means unlimited, e.g. char(10) to string(5) is narrowed
+	switch {
+	case n.Precision > 0 && (o.Precision == 0 || n.Precision-n.Scale < o.Precision-o.Scale || n.Scale < o.Scale):
+		return false
+	case n.Length > 0 && (o.Length == 0 || n.Length < o.Length):
+		return false
+	}
+	return true
+}
+
+// maxClass returns the more severe class.
+func maxClass(a string, b string) string {
+	return lo.Ternary(lo.IndexOf(CompatClasses, a) >= lo.IndexOf(CompatClasses, b), a, b)
+}
+
+// Classify returns the compatibility class of the change, added is true if
+// the table of the change is added, so its constraints are additive. The
+// suspected renames are found by classifyChanges.
+func Classify(c Change, added bool) string {
+	switch c.Object {
+	case ObjectTable:
+		return lo.Ternary(c.Kind == ChangeAdd, CompatAdditive, CompatDestructive)
+	case ObjectColumn:
+		switch c.Kind {
+		case ChangeAdd:
+			// the existing rows and the inserts of the running application have no value
+			column := c.New.Column
+			if isYes(column.NotNull) && column.Value == "" && !isYes(column.Identity) {
+				return CompatNarrowing
+			}
+			return CompatAdditive
+		case ChangeDrop:
+			return CompatDestructive
+		}
+		class := CompatAdditive
+		for _, field := range c.Fields {
+			switch field {
+			case FieldType:
+				class = maxClass(class, lo.Ternary(typeWidened(c.Old.Column.DataType, c.New.Column.DataType), CompatWidening, CompatNarrowing))
+			case FieldNotNull:
+				class = maxClass(class, lo.Ternary(isYes(c.New.Column.NotNull), CompatNarrowing, CompatWidening))
+			case FieldDefault:
+				// the default is the value written by the inserts of the running
+				// application omitting the column, a changed default writes
+				// another value and a removed one writes null (or fails on the
+				// not null column), only a new default keeps the written values
+				class = maxClass(class, lo.Ternary(c.Old.Column.Value == "", CompatAdditive, CompatNarrowing))
+			}
+		}
+		return class
+	case ObjectForeignKey:
+		switch {
+		case added:
+			return CompatAdditive
+		case c.Kind == ChangeDrop:
+			return CompatWidening
+		}
+		// the existing rows may not be referenced
+		return CompatNarrowing
+	case ObjectIndex:
+		switch {
+		case added:
+			return CompatAdditive
+		case c.Kind == ChangeDrop:
+			return CompatWidening
+		case c.New.Index.Unique:
+			// the existing rows may not be unique
+			return CompatNarrowing
+		}
+		return lo.Ternary(c.Kind == ChangeAdd, CompatAdditive, CompatWidening)
+	case ObjectCheck, ObjectUnique:
+		switch {
+		case added:
+			return CompatAdditive
+		case c.Kind == ChangeDrop:
+			return CompatWidening
+		}
+		// the existing rows may not satisfy the constraint
+		return CompatNarrowing
+	}
+	return CompatAdditive
+}
+
+// classifyChanges sets the compatibility classes of the changes. A dropped
+// column and an added column of the same table with the same type and not
+// null are suspected to be renamed, so are a dropped table and an added
+// table with the same columns.
+func classifyChanges(changes []Change) {
+	added := make(map[string]bool)
+	for _, c := range changes {
+		if c.Object == ObjectTable && c.Kind == ChangeAdd {
+			added[c.Table] = true
+		}
+	}
+	for i := range changes {
+		changes[i].Class = Classify(changes[i], added[changes[i].Table])
+	}
+
+	columnKey := func(column Column) string {
+		return typeKey(column.DataType) + lo.Ternary(isYes(column.NotNull), " NOT NULL", "")
+	}
+	tableKey := func(table Table) string {
+		return strings.Join(lo.Map(table.Columns, func(column Column, _ int) string { return column.Name + " " + columnKey(column) }), ",")
+	}
+	rename := func(match func(dropped Change, added Change) bool, object string) {
+		for i := range changes {
+			d := &changes[i]
+			if d.Object != object || d.Kind != ChangeDrop {
+				continue
+			}
+			for j := range changes {
+				a := &changes[j]
+				if a.Object == object && a.Kind == ChangeAdd && a.Rename == "" && match(*d, *a) {
+					d.Class, a.Class = CompatRename, CompatRename
+					d.Rename, a.Rename = lo.Ternary(object == ObjectTable, a.Table, a.Name), lo.Ternary(object == ObjectTable, d.Table, d.Name)
+					break
+				}
+			}
+		}
+	}
+	rename(func(d Change, a Change) bool {
+		return d.Table == a.Table && columnKey(d.Old.Column) == columnKey(a.New.Column)
+	}, ObjectColumn)
+	rename(func(d Change, a Change) bool {
+		return tableKey(d.Old.Table) == tableKey(a.New.Table)
+	}, ObjectTable)
+}
+
+// Breaking returns true if the change may break the running application,
+// which are the narrowing, rename-suspected and destructive changes.
+func (c Change) Breaking() bool {
+	return lo.Contains(breakingClasses, c.Class)
+}
+
+// CheckCompat returns the findings of the changes, the forbidden changes are
+// errors and the other changes except the additive ones are warnings.
+func CheckCompat(changes []Change, policy CompatPolicy) ([]Finding, error) {
+	forbidden, err := policy.Forbidden()
+	if err != nil {
+		return nil, err
+	}
+	result := make([]Finding, 0)
+	for _, c := range changes {
+		forbid := lo.Contains(forbidden, c.Class)
+		if c.Class == CompatAdditive && !forbid {
+			continue
+		}
+		item := lo.Ternary(c.New != nil, c.New, c.Old)
+		pos := item.Table.Pos
+		switch c.Object {
+		case ObjectColumn:
+			pos = item.Column.Pos
+		case ObjectForeignKey:
+			pos = item.ForeignKey.Pos
+		case ObjectIndex:
+			pos = item.Index.Pos
+		case ObjectCheck:
+			pos = item.Check.Pos
+		case ObjectUnique:
+			pos = item.Column.Pos
+		}
+		message := fmt.Sprintf("%s: %s %s %s: %s", c.Class, c.Kind, c.Object, lo.Ternary(c.Name != "", c.Name, c.Table), changeDetail(c))
+		if c.Rename != "" {
+			message += fmt.Sprintf(", renamed %s %s?", lo.Ternary(c.Kind == ChangeDrop, "to", "from"), c.Rename)
+		}
+		result = append(result, Finding{Rule: c.Class, Severity: lo.Ternary(forbid, SeverityError, SeverityWarning),
+			Schema: c.Schema, Table: c.Table, Column: lo.Ternary(c.Object == ObjectColumn || c.Object == ObjectUnique, item.Column.Name, ""), Pos: pos, Message: message})
+	}
+	return result, nil
+}
@@ -0,0 +1,229 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+func TestTypeWidened(t *testing.T) {
+	tests := []struct {
+		old, new string
+		want     bool
+	}{
+		{"string(50)", "string(100)", true},
+		{"string(100)", "string(50)", false},
+		{"string(50)", "string", true},
+		{"string", "string(50)", false},
+		{"char(3)", "string(10)", true},
+		{"char(10)", "string(5)", false},
+		{"string(50)", "text", true},
+		{"text", "string(50)", false},
+		{"int16", "int32", true},
+		{"int32", "int64", true},
+		{"int64", "int32", false},
+		{"int64", "decimal(20)", true},
+		{"int64", "decimal(10,2)", false},
+		{"int32", "decimal", true},
+		{"decimal(10,2)", "decimal(12,2)", true},
+		{"decimal(10,2)", "decimal(11,3)", true},
+		{"decimal(10,2)", "decimal(10,3)", false},
+		{"decimal(10,2)", "decimal(10,1)", false},
+		{"decimal(10,2)", "int64", false},
+		{"float32", "float64", true},
+		{"float64", "float32", false},
+		{"int32", "float64", false},
+		{"bytes(10)", "bytes(20)", true},
+		{"bool", "int16", false},
+		// the common raw types are compared as the logical types
+		{"VARCHAR(50)", "varchar(50)", true},
+		{"VARCHAR(50)", "VARCHAR(100)", true},
+		{"VARCHAR(100)", "VARCHAR(50)", false},
+		{"VARCHAR(50)", "NVARCHAR(MAX)", true},
+		{"NVARCHAR(MAX)", "NVARCHAR(50)", false},
+		{"CHAR(1)", "VARCHAR(10)", true},
+		{"VARCHAR(50)", "TEXT", true},
+		{"VARCHAR(50)", "string(100)", true},
+		{"INT", "BIGINT", true},
+		{"BIGINT", "INTEGER", false},
+		{"SMALLINT", "int", true},
+		{"DECIMAL(10,2)", "NUMERIC(12,2)", true},
+		{"DECIMAL(10,2)", "DECIMAL(10,1)", false},
+		{"REAL", "DOUBLE PRECISION", true},
+		{"FLOAT", "REAL", false},
+		{"INT", "VARCHAR(20)", false},
+		// other raw types are widened only if they are the same
+		{"DATETIME", "datetime", true},
+		{"DATETIME", "DATETIME2", false},
+		{"string(100)", "string(200", false},
+	}
+	for _, tt := range tests {
+		if got := typeWidened(tt.old, tt.new); got != tt.want {
+			t.Errorf("typeWidened(%q, %q) = %v, want %v", tt.old, tt.new, got, tt.want)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	column := func(dataType string, notNull string, value string) *ChangeItem {
+		return &ChangeItem{Column: Column{Name: "c", DataType: dataType, NotNull: notNull, Value: value}}
+	}
+	tests := []struct {
+		name  string
+		c     Change
+		added bool
+		want  string
+	}{
+		{"add table", Change{Kind: ChangeAdd, Object: ObjectTable}, true, CompatAdditive},
+		{"drop table", Change{Kind: ChangeDrop, Object: ObjectTable}, false, CompatDestructive},
+		{"add nullable column", Change{Kind: ChangeAdd, Object: ObjectColumn, New: column("int32", "", "")}, false, CompatAdditive},
+		{"add not null column with default", Change{Kind: ChangeAdd, Object: ObjectColumn, New: column("int32", "Y", "0")}, false, CompatAdditive},
+		{"add not null column", Change{Kind: ChangeAdd, Object: ObjectColumn, New: column("int32", "Y", "")}, false, CompatNarrowing},
+		{"add identity column", Change{Kind: ChangeAdd, Object: ObjectColumn,
+			New: &ChangeItem{Column: Column{DataType: "int64", NotNull: "Y", Identity: "Y"}}}, false, CompatAdditive},
+		{"drop column", Change{Kind: ChangeDrop, Object: ObjectColumn, Old: column("int32", "", "")}, false, CompatDestructive},
+		{"widen type", Change{Kind: ChangeAlter, Object: ObjectColumn, Fields: []string{FieldType},
+			Old: column("string(10)", "", ""), New: column("string(20)", "", "")}, false, CompatWidening},
+		{"narrow type", Change{Kind: ChangeAlter, Object: ObjectColumn, Fields: []string{FieldType},
+			Old: column("string(20)", "", ""), New: column("string(10)", "", "")}, false, CompatNarrowing},
+		{"set not null", Change{Kind: ChangeAlter, Object: ObjectColumn, Fields: []string{FieldNotNull},
+			Old: column("int32", "", ""), New: column("int32", "Y", "")}, false, CompatNarrowing},
+		{"drop not null and widen type", Change{Kind: ChangeAlter, Object: ObjectColumn, Fields: []string{FieldType, FieldNotNull},
+			Old: column("int32", "Y", ""), New: column("int64", "", "")}, false, CompatWidening},
+		{"add default", Change{Kind: ChangeAlter, Object: ObjectColumn, Fields: []string{FieldDefault},
+			Old: column("int32", "", ""), New: column("int32", "", "0")}, false, CompatAdditive},
+		{"change default", Change{Kind: ChangeAlter, Object: ObjectColumn, Fields: []string{FieldDefault},
+			Old: column("int32", "", "0"), New: column("int32", "", "1")}, false, CompatNarrowing},
+		{"drop default", Change{Kind: ChangeAlter, Object: ObjectColumn, Fields: []string{FieldDefault},
+			Old: column("int32", "Y", "0"), New: column("int32", "Y", "")}, false, CompatNarrowing},
+		{"drop not null and add default", Change{Kind: ChangeAlter, Object: ObjectColumn, Fields: []string{FieldNotNull, FieldDefault},
+			Old: column("int32", "Y", ""), New: column("int32", "", "0")}, false, CompatWidening},
+		{"widen raw type", Change{Kind: ChangeAlter, Object: ObjectColumn, Fields: []string{FieldType},
+			Old: column("VARCHAR(50)", "", ""), New: column("VARCHAR(100)", "", "")}, false, CompatWidening},
+		{"add foreign key", Change{Kind: ChangeAdd, Object: ObjectForeignKey, New: &ChangeItem{}}, false, CompatNarrowing},
+		{"add foreign key of added table", Change{Kind: ChangeAdd, Object: ObjectForeignKey, New: &ChangeItem{}}, true, CompatAdditive},
+		{"drop foreign key", Change{Kind: ChangeDrop, Object: ObjectForeignKey, Old: &ChangeItem{}}, false, CompatWidening},
+		{"add index", Change{Kind: ChangeAdd, Object: ObjectIndex, New: &ChangeItem{}}, false, CompatAdditive},
+		{"add unique index", Change{Kind: ChangeAdd, Object: ObjectIndex, New: &ChangeItem{Index: Index{Unique: true}}}, false, CompatNarrowing},
+		{"alter index", Change{Kind: ChangeAlter, Object: ObjectIndex, Old: &ChangeItem{}, New: &ChangeItem{}}, false, CompatWidening},
+		{"drop unique index", Change{Kind: ChangeDrop, Object: ObjectIndex, Old: &ChangeItem{Index: Index{Unique: true}}}, false, CompatWidening},
+		{"add check", Change{Kind: ChangeAdd, Object: ObjectCheck, New: &ChangeItem{}}, false, CompatNarrowing},
+		{"alter check", Change{Kind: ChangeAlter, Object: ObjectCheck, Old: &ChangeItem{}, New: &ChangeItem{}}, false, CompatNarrowing},
+		{"drop check", Change{Kind: ChangeDrop, Object: ObjectCheck, Old: &ChangeItem{}}, false, CompatWidening},
+		{"add unique", Change{Kind: ChangeAdd, Object: ObjectUnique, New: &ChangeItem{}}, false, CompatNarrowing},
+		{"add unique of added table", Change{Kind: ChangeAdd, Object: ObjectUnique, New: &ChangeItem{}}, true, CompatAdditive},
+		{"drop unique", Change{Kind: ChangeDrop, Object: ObjectUnique, Old: &ChangeItem{}}, false, CompatWidening},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Classify(tt.c, tt.added); got != tt.want {
+				t.Errorf("Classify() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+const compatOld = `schemas:
+  - name: app
+    tables:
+      - name: doc
+        columns:
+          - {na: doc_id, ty: int32, id: Y, nu: Y}
+          - {na: title, ty: string(50), nu: Y}
+          - {na: ref, ty: string(20)}
+          - {na: note, ty: text}
+          - {na: ver, ty: int32}
+      - name: tag
+        columns:
+          - {na: tag_id, ty: int32, id: Y, nu: Y}
+          - {na: name, ty: string(20), nu: Y}
+`
+
+const compatNew = `schemas:
+  - name: app
+    tables:
+      - name: doc
+        columns:
+          - {na: doc_id, ty: int64, id: Y, nu: Y}
+          - {na: name, ty: string(50), nu: Y}
+          - {na: ref, ty: string(10)}
+          - {na: ver, ty: int32, nu: Y}
+          - {na: kind, ty: char(3)}
+      - name: label
+        columns:
+          - {na: tag_id, ty: int32, id: Y, nu: Y}
+          - {na: name, ty: string(20), nu: Y}
+`
+
+func TestClassifyChanges(t *testing.T) {
+	changes := Diff(readTestYml(t, compatOld), readTestYml(t, compatNew))
+	got := lo.Map(changes, func(c Change, _ int) string {
+		s := c.Kind + " " + c.Object + " " + lo.Ternary(c.Name != "", c.Name, c.Table) + ": " + c.Class
+		return s + lo.Ternary(c.Rename != "", " "+c.Rename, "")
+	})
+	want := []string{
+		"alter column doc_id: widening",
+		"add column name: rename-suspected title",
+		"alter column ref: narrowing",
+		"alter column ver: narrowing",
+		"add column kind: additive",
+		"drop column title: rename-suspected name",
+		"drop column note: destructive",
+		"add table label: rename-suspected tag",
+		"drop table tag: rename-suspected label",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCheckCompat(t *testing.T) {
+	changes := Diff(readTestYml(t, compatOld), readTestYml(t, compatNew))
+	tests := []struct {
+		name   string
+		forbid []string
+		errors []string // the classes of the errors, which fail the compat command
+		warns  []string // the classes of the warnings
+	}{
+		{"default", nil,
+			[]string{CompatRename, CompatNarrowing, CompatDestructive}, []string{CompatWidening}},
+		{"destructive", []string{" Destructive "},
+			[]string{CompatDestructive}, []string{CompatRename, CompatWidening, CompatNarrowing}},
+		{"additive", []string{CompatAdditive},
+			[]string{CompatAdditive}, []string{CompatRename, CompatWidening, CompatNarrowing, CompatDestructive}},
+		{"widening and narrowing", []string{CompatWidening, CompatNarrowing},
+			[]string{CompatWidening, CompatNarrowing}, []string{CompatRename, CompatDestructive}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			findings, err := CheckCompat(changes, CompatPolicy{Forbid: tt.forbid})
+			if err != nil {
+				t.Fatal(err)
+			}
+			classes := func(severity string) []string {
+				return lo.Uniq(lo.FilterMap(findings, func(f Finding, _ int) (string, bool) { return f.Rule, f.Severity == severity }))
+			}
+			if got := classes(SeverityError); !sameStrings(got, tt.errors) {
+				t.Errorf("error classes = %v, want %v", got, tt.errors)
+			}
+			if got := classes(SeverityWarning); !sameStrings(got, tt.warns) {
+				t.Errorf("warning classes = %v, want %v", got, tt.warns)
+			}
+		})
+	}
+
+	if _, err := CheckCompat(changes, CompatPolicy{Forbid: []string{"breaking"}}); err == nil {
+		t.Error("expected an error for the unknown class")
+	}
+	// no error, the compat command exits with zero
+	findings, err := CheckCompat(Diff(readTestYml(t, compatOld), readTestYml(t, compatOld)), CompatPolicy{})
+	if err != nil || len(findings) > 0 {
+		t.Errorf("findings of no change = %v, %v", findings, err)
+	}
+}
+
+// sameStrings returns true if the lists have the same strings in any order.
+func sameStrings(a []string, b []string) bool {
+	return len(a) == len(b) && len(lo.Intersect(a, b)) == len(a)
+}
@@ -0,0 +1,47 @@
+package transform
+
+import (
+	"errors"
+	"os"
+
+	"github.com/ztrue/tracerr"
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultConfigFile is the configuration file loaded from the current
+// directory if no file is given.
+const DefaultConfigFile = ".dst.yml"
+
+// Config is the project configuration.
+type Config struct {
+	// Types overrides the type mappings of the logical types for each dialect,
+	// e.g. postgres: { json: JSON }
+	Types TypeMappings `yaml:"types,omitempty"`
+	// Compat is the policy of the compatibility check, e.g. forbid: [destructive]
+	Compat CompatPolicy `yaml:"compat,omitempty"`
+	// Lint is the configuration of the lint rules of verify, e.g. rules: { description: warning }
+	Lint LintConfig `yaml:"lint,omitempty"`
+}
+
+// LoadConfig reads the configuration file, an empty configuration is returned
+// if the file is the default one and not exists.
+func LoadConfig(file string) (*Config, error) {
+	if file == "" {
+		file = DefaultConfigFile
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && file == DefaultConfigFile {
+			return &Config{}, nil
+		}
+		return nil, tracerr.Wrap(err)
+	}
+	var cfg Config
+	if err := yaml.Unmarshal(content, &cfg); err != nil {
+		return nil, tracerr.Errorf("%s: %s", file, err.Error())
+	}
+	if err := cfg.Lint.validate(); err != nil {
+		return nil, tracerr.Errorf("%s: %s", file, tracerr.Unwrap(err).Error())
+	}
+	return &cfg, nil
+}
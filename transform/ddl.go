@@ -0,0 +1,204 @@
+package transform
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// helpers shared by the built-in DDL writers
+
+var (
+	simpleIdentRegexp = regexp.MustCompile(`^[a-z_][a-z0-9_]*$`)
+	numberRegexp      = regexp.MustCompile(`^[-+]?[0-9]+(\.[0-9]+)?$`)
+	funcCallRegexp    = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*\(.*\)$`)
+)
+
+// sqlKeywords are the default values passed through without quotes
+var sqlKeywords = []string{"NULL", "TRUE", "FALSE", "CURRENT_TIMESTAMP", "CURRENT_DATE", "CURRENT_TIME", "CURRENT_USER", "LOCALTIMESTAMP"}
+
+// pgReservedWords are the reserved key words of PostgreSQL, which must be quoted
+// when used as identifier.
+var pgReservedWords = []string{
+	"all", "analyse", "analyze", "and", "any", "array", "as", "asc", "asymmetric", "both", "case", "cast",
+	"check", "collate", "column", "constraint", "create", "current_catalog", "current_date", "current_role",
+	"current_time", "current_timestamp", "current_user", "default", "deferrable", "desc", "distinct", "do",
+	"else", "end", "except", "false", "fetch", "for", "foreign", "from", "grant", "group", "having", "in",
+	"initially", "intersect", "into", "lateral", "leading", "limit", "localtime", "localtimestamp", "not",
+	"null", "offset", "on", "only", "or", "order", "placing", "primary", "references", "returning", "select",
+	"session_user", "some", "symmetric", "table", "then", "to", "trailing", "true", "union", "unique", "user",
+	"using", "variadic", "when", "where", "window", "with",
+}
+
+// isYes returns true if the flag value is Y (case insensitive).
+func isYes(v string) bool {
+	return strings.EqualFold(strings.TrimSpace(v), "Y")
+}
+
+// quoteIdent quotes the identifier with the quote char if it is not a simple
+// lower case identifier or it is a reserved word.
+func quoteIdent(name string, quote string, reserved []string) string {
+	if simpleIdentRegexp.MatchString(name) && !lo.Contains(reserved, name) {
+		return name
+	}
+	return quote + strings.ReplaceAll(name, quote, quote+quote) + quote
+}
+
+// quoteIdents returns the identifiers quoted by qi and separated by comma.
+func quoteIdents(names []string, qi func(string) string) string {
+	return strings.Join(lo.Map(names, func(name string, _ int) string { return qi(name) }), ", ")
+}
+
+// quoteString returns the SQL string literal of the value.
+func quoteString(v string) string {
+	return "'" + strings.ReplaceAll(v, "'", "''") + "'"
+}
+
+// sqlDefault returns the SQL expression of the default value, the numbers,
+// keywords and function calls are used as is, otherwise a string literal.
+func sqlDefault(v string) string {
+	switch {
+	case numberRegexp.MatchString(v):
+		return v
+	case lo.Contains(sqlKeywords, strings.ToUpper(v)):
+		return strings.ToUpper(v)
+	case funcCallRegexp.MatchString(v):
+		return v
+	case strings.HasPrefix(v, "'") && strings.HasSuffix(v, "'") && len(v) > 1:
+		return v
+	}
+	return quoteString(v)
+}
+
+// splitForeignKey splits the foreign key hint (table.column) into table and
+// column, ok is false if the format is invalid.
+func splitForeignKey(fk string) (table string, column string, ok bool) {
+	parts := strings.Split(fk, ".")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", false
+	}
+	return strings.TrimSpace(parts[0]), strings.TrimSpace(parts[1]), true
+}
+
+// tableSchemas returns the map of the table name and its schema name.
+func tableSchemas(data *DataDef) map[string]string {
+	result := make(map[string]string)
+	for _, schema := range data.Schemas {
+		for _, table := range schema.Tables {
+			result[table.Name] = schema.Name
+		}
+	}
+	return result
+}
+
+// findTable returns the table of the name in any schema.
+func findTable(data *DataDef, name string) (Table, bool) {
+	for _, schema := range data.Schemas {
+		for _, table := range schema.Tables {
+			if table.Name == name {
+				return table, true
+			}
+		}
+	}
+	return Table{}, false
+}
+
+// fkActions are the referential actions of ON DELETE and ON UPDATE
+var fkActions = []string{"CASCADE", "SET NULL", "SET DEFAULT", "RESTRICT", "NO ACTION"}
+
+// fkName returns the constraint name of the foreign key, the name is
+// fk_<table>_<columns> if not defined.
+func fkName(table string, fk ForeignKey) string {
+	if fk.Name != "" {
+		return fk.Name
+	}
+	return "fk_" + table + "_" + strings.Join(fk.Columns, "_")
+}
+
+// fkActionClause returns the ON DELETE and ON UPDATE clause of the foreign
+// key with a leading space, or empty if no action defined.
+func fkActionClause(fk ForeignKey) string {
+	clause := ""
+	if fk.OnDelete != "" {
+		clause += " ON DELETE " + strings.ToUpper(fk.OnDelete)
+	}
+	if fk.OnUpdate != "" {
+		clause += " ON UPDATE " + strings.ToUpper(fk.OnUpdate)
+	}
+	return clause
+}
+
+// uniqueName returns the name of the unique constraint of the column, which
+// is uq_<table>_<column>.
+func uniqueName(table string, column string) string {
+	return "uq_" + table + "_" + column
+}
+
+// splitIndexColumn splits the index column into the column name and the sort
+// order (ASC or DESC, empty if not defined).
+func splitIndexColumn(column string) (name string, order string) {
+	fields := strings.Fields(column)
+	switch len(fields) {
+	case 0:
+		return "", ""
+	case 1:
+		return fields[0], ""
+	}
+	return strings.Join(fields[:len(fields)-1], " "), strings.ToUpper(fields[len(fields)-1])
+}
+
+// indexName returns the name of the index, the name is
+// idx_<table>_<columns> if not defined.
+func indexName(table string, ix Index) string {
+	if ix.Name != "" {
+		return ix.Name
+	}
+	return "idx_" + table + "_" + strings.Join(ix.ColumnNames(), "_")
+}
+
+// indexColumns returns the columns of the index with the sort order, the
+// names are quoted by qi.
+func indexColumns(ix Index, qi func(string) string) string {
+	columns := make([]string, 0, len(ix.Columns))
+	for _, column := range ix.Columns {
+		name, order := splitIndexColumn(column)
+		columns = append(columns, strings.TrimSpace(qi(name)+" "+order))
+	}
+	return strings.Join(columns, ", ")
+}
+
+// sqlValue returns the literal of the value, the numbers are not quoted.
+func sqlValue(v string) string {
+	if numberRegexp.MatchString(v) {
+		return v
+	}
+	return quoteString(v)
+}
+
+// tableChecks returns the check constraints of the table, see
+// DataDef.Checks, the column names of the enum columns are quoted by qi.
+func tableChecks(data *DataDef, table Table, qi func(string) string) []Check {
+	result := make([]Check, 0)
+	for _, column := range tableColumns(data, table) {
+		if len(column.Enum) > 0 {
+			values := lo.Map(column.Enum.Values(), func(v string, _ int) string { return sqlValue(v) })
+			result = append(result, Check{Name: "ck_" + table.Name + "_" + column.Name, Pos: column.Pos,
+				Expr: fmt.Sprintf("%s IN (%s)", qi(column.Name), strings.Join(values, ", "))})
+		}
+	}
+	for i, check := range table.Checks {
+		if check.Name == "" {
+			check.Name = fmt.Sprintf("ck_%s_%d", table.Name, i+1)
+		}
+		result = append(result, check)
+	}
+	return result
+}
+
+// tableColumns returns the columns of the table with the fixed columns
+// appended unless the table excludes them.
+func tableColumns(data *DataDef, table Table) []Column {
+	return append(append([]Column{}, table.Columns...), table.FixedColumns(data.Fixed)...)
+}
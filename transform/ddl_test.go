@@ -0,0 +1,53 @@
+package transform
+
+import (
+	"testing"
+)
+
+const checksYml = `schemas:
+  - name: app
+    tables:
+      - name: doc
+        columns:
+          - { na: doc_id, ty: INT, id: Y, nu: Y }
+          - { na: kind, ty: CHAR(3), nu: Y, enum: [COM, PRE], va: COM }
+          - { na: state, ty: CHAR(1), enum: { A: active, D: "deleted, it's gone" } }
+          - { na: ver, ty: INT, nu: Y }
+        checks:
+          - { expr: ver >= 0 }
+          - { name: ck_doc_state_ver, expr: "state <> 'D' OR ver > 0" }
+`
+
+func TestWriteChecks(t *testing.T) {
+	data := readTestYml(t, checksYml)
+	// the enum checks of the columns are followed by the checks of the table,
+	// the unnamed checks are numbered
+	checks := []string{
+		"CONSTRAINT ck_doc_kind CHECK (kind IN ('COM', 'PRE'))",
+		"CONSTRAINT ck_doc_state CHECK (state IN ('A', 'D'))",
+		"CONSTRAINT ck_doc_1 CHECK (ver >= 0)",
+		"CONSTRAINT ck_doc_state_ver CHECK (state <> 'D' OR ver > 0)",
+	}
+	tests := []struct {
+		name  string
+		write func(out string) error
+	}{
+		{"mariadb", func(out string) error { return WriteTpl(data, "mariadb", out, "", nil) }},
+		{"mssql-create", func(out string) error { return WriteTpl(data, "mssql-create", out, "", nil) }},
+		{"postgres", func(out string) error { return WritePostgres(data, out, nil) }},
+		{"sqlite", func(out string) error { return WriteSqlite(data, out, nil) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertInOrder(t, readTestOutput(t, "out.sql", tt.write), checks...)
+		})
+	}
+
+	// the checks are added to the existing table
+	script := readTestOutput(t, "out.sql", func(out string) error { return WriteTpl(data, "mssql-alter", out, "", nil) })
+	assertInOrder(t, script,
+		"IF OBJECT_ID(N'ck_doc_kind', N'C') IS NULL ALTER TABLE doc ADD CONSTRAINT ck_doc_kind CHECK (kind IN ('COM', 'PRE'));",
+		"IF OBJECT_ID(N'ck_doc_state_ver', N'C') IS NULL ALTER TABLE doc ADD CONSTRAINT ck_doc_state_ver CHECK (state <> 'D' OR ver > 0);",
+		"IF OBJECT_ID(N'ck_doc_kind', N'C') IS NOT NULL ALTER TABLE doc DROP CONSTRAINT ck_doc_kind;",
+	)
+}
@@ -0,0 +1,267 @@
+package transform
+
+import (
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// the kinds of the changes
+const (
+	ChangeAdd   = "add"
+	ChangeDrop  = "drop"
+	ChangeAlter = "alter"
+)
+
+// the objects of the changes
+const (
+	ObjectTable      = "table"
+	ObjectColumn     = "column"
+	ObjectForeignKey = "foreign-key"
+	ObjectIndex      = "index"
+	ObjectCheck      = "check"
+	ObjectUnique     = "unique"
+)
+
+// the properties of the altered column
+const (
+	FieldType    = "type"
+	FieldNotNull = "not-null"
+	FieldDefault = "default"
+)
+
+// Change is a difference of a table, column, foreign key, index, check or
+// unique constraint between two definitions. Old is nil if the object is
+// added, New is nil if it is dropped.
+type Change struct {
+	Kind   string      // add, drop or alter
+	Object string      // table, column, foreign-key, index, check or unique
+	Schema string      // schema of the table
+	Table  string      // name of the table
+	Name   string      // name of the column or constraint, empty for the table
+	Fields []string    // changed properties of the altered column, see FieldType
+	Class  string      // compatibility class, see Classify
+	Rename string      // the other name of the suspected renamed table or column
+	Old    *ChangeItem // the object in the old definition
+	New    *ChangeItem // the object in the new definition
+}
+
+// ChangeItem is the object of a change with its table, the table is
+// flattened by diffTable. Only the field of the changed object is set, the
+// unique constraint is the unique column.
+type ChangeItem struct {
+	Table      Table
+	Column     Column
+	ForeignKey ForeignKey
+	Index      Index
+	Check      Check
+}
+
+// diffTable returns the self-contained copy of the table for comparing, the
+// fixed columns are appended, the primary key, foreign keys (including the
+// fk of the columns) and indexes (including the in of the columns) are moved
+// to the table level.
+func diffTable(data *DataDef, table Table) Table {
+	t := table
+	t.PrimaryKey = table.PrimaryKeyColumns(data.Fixed)
+	t.ForeignKeys = data.ForeignKeys(table)
+	t.Indexes = tableIndexes(data, table)
+	t.Checks = append([]Check{}, table.Checks...)
+	t.Columns = lo.Map(tableColumns(data, table), func(c Column, _ int) Column {
+		c.ForeignKey, c.Index = "", ""
+		return c
+	})
+	t.ExcludeFixed = true
+	t.OutColumns = nil
+	return t
+}
+
+// tableIndexes returns the indexes of the table, which are the indexes of the
+// columns (in: Y, the unique columns are indexed by the constraint) followed
+// by the indexes of the table.
+func tableIndexes(data *DataDef, table Table) []Index {
+	result := make([]Index, 0)
+	for _, column := range tableColumns(data, table) {
+		if isYes(column.Index) && !isYes(column.Unique) {
+			result = append(result, Index{Columns: []string{column.Name}, Pos: column.Pos})
+		}
+	}
+	return append(result, table.Indexes...)
+}
+
+// typeKey returns the data type for comparing, the logical types are
+// normalized and the raw types are compared case insensitive.
+func typeKey(dataType string) string {
+	if t, err := ParseType(dataType); err == nil && t.Logical {
+		return t.String()
+	}
+	return strings.ToUpper(strings.ReplaceAll(dataType, " ", ""))
+}
+
+// columnFields returns the changed properties of the column.
+func columnFields(old Column, new Column) []string {
+	fields := make([]string, 0)
+	if typeKey(old.DataType) != typeKey(new.DataType) {
+		fields = append(fields, FieldType)
+	}
+	if isYes(old.NotNull) != isYes(new.NotNull) {
+		fields = append(fields, FieldNotNull)
+	}
+	if old.Value != new.Value {
+		fields = append(fields, FieldDefault)
+	}
+	return fields
+}
+
+// foreignKeyKey returns the definition of the foreign key for comparing.
+func foreignKeyKey(fk ForeignKey) string {
+	return strings.Join(fk.Columns, ",") + ">" + fk.RefTable + "(" + strings.Join(fk.RefColumns, ",") + ")" + fkActionClause(fk)
+}
+
+// indexKey returns the definition of the index for comparing.
+func indexKey(ix Index) string {
+	return strings.Join(ix.Columns, ",") + lo.Ternary(ix.Unique, " UNIQUE", "") +
+		" INCLUDE " + strings.Join(ix.Include, ",") + " WHERE " + ix.Where
+}
+
+// Diff returns the changes from the old definition to the new one, the
+// definitions are resolved (see Resolve) and the tables and columns are
+// matched by name, the foreign keys, indexes and checks (including the enum
+// values of the columns) by the effective name. The changes are in the order
+// of the new definition followed by the dropped tables, the foreign keys and
+// indexes of an added table are added as well, its checks and unique
+// constraints are created with the table. The tables are matched by name
+// only, a table moved to other schema is not a change.
+func Diff(oldData *DataDef, newData *DataDef) []Change {
+	oldData, newData = Resolve(oldData), Resolve(newData)
+
+	flatten := func(data *DataDef) []TableRef {
+		refs := make([]TableRef, 0)
+		for _, schema := range data.Schemas {
+			for _, table := range schema.Tables {
+				refs = append(refs, TableRef{Schema: schema.Name, Table: diffTable(data, table)})
+			}
+		}
+		return refs
+	}
+	oldTables, newTables := flatten(oldData), flatten(newData)
+	findRef := func(refs []TableRef, name string) (TableRef, bool) {
+		return lo.Find(refs, func(ref TableRef) bool { return ref.Table.Name == name })
+	}
+
+	changes := make([]Change, 0)
+	add := func(kind string, object string, ref TableRef, name string, old *ChangeItem, new *ChangeItem) {
+		changes = append(changes, Change{Kind: kind, Object: object, Schema: ref.Schema, Table: ref.Table.Name, Name: name, Old: old, New: new})
+	}
+
+	for _, nref := range newTables {
+		ntable := nref.Table
+		oref, found := findRef(oldTables, ntable.Name)
+		if !found {
+			add(ChangeAdd, ObjectTable, nref, "", nil, &ChangeItem{Table: ntable})
+			for _, fk := range ntable.ForeignKeys {
+				add(ChangeAdd, ObjectForeignKey, nref, fkName(ntable.Name, fk), nil, &ChangeItem{Table: ntable, ForeignKey: fk})
+			}
+			for _, ix := range ntable.Indexes {
+				add(ChangeAdd, ObjectIndex, nref, indexName(ntable.Name, ix), nil, &ChangeItem{Table: ntable, Index: ix})
+			}
+			continue
+		}
+		otable := oref.Table
+
+		// columns
+		for _, ncolumn := range ntable.Columns {
+			ocolumn, found := lo.Find(otable.Columns, func(c Column) bool { return c.Name == ncolumn.Name })
+			if !found {
+				add(ChangeAdd, ObjectColumn, nref, ncolumn.Name, nil, &ChangeItem{Table: ntable, Column: ncolumn})
+			} else if fields := columnFields(ocolumn, ncolumn); len(fields) > 0 {
+				add(ChangeAlter, ObjectColumn, nref, ncolumn.Name, &ChangeItem{Table: otable, Column: ocolumn}, &ChangeItem{Table: ntable, Column: ncolumn})
+				changes[len(changes)-1].Fields = fields
+			}
+		}
+		for _, ocolumn := range otable.Columns {
+			if !lo.ContainsBy(ntable.Columns, func(c Column) bool { return c.Name == ocolumn.Name }) {
+				add(ChangeDrop, ObjectColumn, nref, ocolumn.Name, &ChangeItem{Table: otable, Column: ocolumn}, nil)
+			}
+		}
+
+		// foreign keys
+		for _, nfk := range ntable.ForeignKeys {
+			name := fkName(ntable.Name, nfk)
+			ofk, found := lo.Find(otable.ForeignKeys, func(fk ForeignKey) bool { return fkName(otable.Name, fk) == name })
+			if !found {
+				add(ChangeAdd, ObjectForeignKey, nref, name, nil, &ChangeItem{Table: ntable, ForeignKey: nfk})
+			} else if foreignKeyKey(ofk) != foreignKeyKey(nfk) {
+				add(ChangeAlter, ObjectForeignKey, nref, name, &ChangeItem{Table: otable, ForeignKey: ofk}, &ChangeItem{Table: ntable, ForeignKey: nfk})
+			}
+		}
+		for _, ofk := range otable.ForeignKeys {
+			name := fkName(otable.Name, ofk)
+			if !lo.ContainsBy(ntable.ForeignKeys, func(fk ForeignKey) bool { return fkName(ntable.Name, fk) == name }) {
+				add(ChangeDrop, ObjectForeignKey, nref, name, &ChangeItem{Table: otable, ForeignKey: ofk}, nil)
+			}
+		}
+
+		// indexes
+		for _, nix := range ntable.Indexes {
+			name := indexName(ntable.Name, nix)
+			oix, found := lo.Find(otable.Indexes, func(ix Index) bool { return indexName(otable.Name, ix) == name })
+			if !found {
+				add(ChangeAdd, ObjectIndex, nref, name, nil, &ChangeItem{Table: ntable, Index: nix})
+			} else if indexKey(oix) != indexKey(nix) {
+				add(ChangeAlter, ObjectIndex, nref, name, &ChangeItem{Table: otable, Index: oix}, &ChangeItem{Table: ntable, Index: nix})
+			}
+		}
+		for _, oix := range otable.Indexes {
+			name := indexName(otable.Name, oix)
+			if !lo.ContainsBy(ntable.Indexes, func(ix Index) bool { return indexName(ntable.Name, ix) == name }) {
+				add(ChangeDrop, ObjectIndex, nref, name, &ChangeItem{Table: otable, Index: oix}, nil)
+			}
+		}
+
+		// unique constraints of the columns
+		isUnique := func(columns []Column, name string) bool {
+			return lo.ContainsBy(columns, func(c Column) bool { return c.Name == name && isYes(c.Unique) })
+		}
+		for _, ncolumn := range ntable.Columns {
+			if isYes(ncolumn.Unique) && !isUnique(otable.Columns, ncolumn.Name) {
+				add(ChangeAdd, ObjectUnique, nref, uniqueName(ntable.Name, ncolumn.Name), nil, &ChangeItem{Table: ntable, Column: ncolumn})
+			}
+		}
+		for _, ocolumn := range otable.Columns {
+			if isYes(ocolumn.Unique) && !isUnique(ntable.Columns, ocolumn.Name) {
+				add(ChangeDrop, ObjectUnique, nref, uniqueName(otable.Name, ocolumn.Name), &ChangeItem{Table: otable, Column: ocolumn}, nil)
+			}
+		}
+
+		// checks
+		plain := func(name string) string { return name }
+		nchecks, ochecks := tableChecks(&DataDef{}, ntable, plain), tableChecks(&DataDef{}, otable, plain)
+		for _, ncheck := range nchecks {
+			ocheck, found := lo.Find(ochecks, func(ck Check) bool { return ck.Name == ncheck.Name })
+			if !found {
+				add(ChangeAdd, ObjectCheck, nref, ncheck.Name, nil, &ChangeItem{Table: ntable, Check: ncheck})
+			} else if ocheck.Expr != ncheck.Expr {
+				add(ChangeAlter, ObjectCheck, nref, ncheck.Name, &ChangeItem{Table: otable, Check: ocheck}, &ChangeItem{Table: ntable, Check: ncheck})
+			}
+		}
+		for _, ocheck := range ochecks {
+			if !lo.ContainsBy(nchecks, func(ck Check) bool { return ck.Name == ocheck.Name }) {
+				add(ChangeDrop, ObjectCheck, nref, ocheck.Name, &ChangeItem{Table: otable, Check: ocheck}, nil)
+			}
+		}
+	}
+
+	// dropped tables, the referencing tables are dropped first, the foreign
+	// keys and indexes are dropped with the table
+	sorted := SortTables(oldData)
+	for i := len(sorted) - 1; i >= 0; i-- {
+		name := sorted[i].Table.Name
+		if _, found := findRef(newTables, name); !found {
+			oref, _ := findRef(oldTables, name)
+			add(ChangeDrop, ObjectTable, oref, "", &ChangeItem{Table: oref.Table}, nil)
+		}
+	}
+	classifyChanges(changes)
+	return changes
+}
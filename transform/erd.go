@@ -6,28 +6,60 @@ import (
 	"path/filepath"
 	"strings"
 
-	"github.com/CloudyKit/jet/v6"
 	"github.com/codeskyblue/go-sh"
 	"github.com/rotisserie/eris"
 	"github.com/ztrue/tracerr"
 )
 
-func WriteERD(data *DataDef, tplf string, out string) error {
+func init() {
+	Register(Format{
+		Name:    "diagram",
+		Aliases: []string{"d"},
+		Usage:   "ER diagram in plantuml, png or svg",
+		Exts:    []string{".puml", ".png", ".svg"},
+		Options: []string{OptTemplate, OptLib},
+		Writer: WriterFunc(func(data *DataDef, out string, opts Options) error {
+			return WriteERD(data, opts.Template, out, opts.Lib, opts.Types)
+		}),
+	})
+}
+
+// DefaultERDTemplate is the built-in template used if no template is given.
+const DefaultERDTemplate = "erd"
+
+// WriteERD writes the plantuml ER diagram file using the template, the image
+// (.png or .svg) is generated by plantuml.jar if the output is not a .puml
+// file. The lib is the path of plantuml.jar, it is searched in the current
+// directory and PATH environment variable if empty. The types are the type
+// mappings of the template, nil for the built-in ones.
+func WriteERD(data *DataDef, tplf string, out string, lib string, types TypeMappings) error {
+	if tplf == "" {
+		tplf = DefaultERDTemplate
+	}
 	// plantuml file name = output file name + .puml
+	ext := strings.ToLower(filepath.Ext(out))
 	outPuml := strings.TrimSuffix(out, filepath.Ext(out)) + ".puml"
-	if err := writePlantuml(data, tplf, outPuml); err != nil {
+	if out == "" {
+		outPuml = ""
+	}
+	if err := writePlantuml(data, tplf, outPuml, types); err != nil {
 		return tracerr.Wrap(err)
 	}
-	if out != "" {
-		lib, err := SearchPathFiles("plantuml*.jar")
-		if err != nil {
-			return tracerr.Wrap(err)
+	if out != "" && ext != ".puml" {
+		if lib == "" {
+			libs, err := SearchPathFiles("plantuml*.jar")
+			if err != nil {
+				return tracerr.New("plantuml*.jar not found in PATH environment variable")
+			}
+			lib = libs[0]
 		}
-		if len(lib) == 0 {
-			return tracerr.New("plantuml*.jar not found in PATH environment variable")
+		fmt.Printf("> use plantuml library found in '%s'\n", lib)
+		args := []interface{}{"-jar", lib}
+		if ext == ".svg" {
+			args = append(args, "-tsvg")
 		}
-		fmt.Printf("> use plantuml library found in '%s'\n", lib[0])
-		if err := sh.Command("java", "-jar", lib[0], outPuml).Run(); err != nil {
+		args = append(args, outPuml)
+		if err := sh.Command("java", args...).Run(); err != nil {
 			return eris.Wrapf(err, "failed to generate the diagram")
 		}
 	}
@@ -35,11 +67,8 @@ func WriteERD(data *DataDef, tplf string, out string) error {
 	return nil
 }
 
-func writePlantuml(data *DataDef, tplf string, out string) error {
-	loader := jet.NewOSFileSystemLoader(filepath.Dir(tplf))
-
-	views := jet.NewSet(loader)
-	view, err := views.GetTemplate(filepath.Base(tplf))
+func writePlantuml(data *DataDef, tplf string, out string, types TypeMappings) error {
+	view, err := getTemplate(tplf, types)
 	if err != nil {
 		return tracerr.Wrap(err)
 	}
@@ -0,0 +1,144 @@
+package transform
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+)
+
+// Options are the options passed to the readers and writers, each format
+// declares the options it uses in Format.Options.
+type Options struct {
+	Template string       // template file, used by the text and diagram output
+	Simple   bool         // simple content
+	Lib      string       // plantuml.jar file, used by the image output
+	Types    TypeMappings // type mappings of the logical types, the built-in ones if nil
+}
+
+// names of the options, used to declare the options used by a format
+const (
+	OptTemplate = "template"
+	OptSimple   = "simple"
+	OptLib      = "lib"
+)
+
+// Reader reads the definition from the file.
+type Reader interface {
+	Read(file string, opts Options) (*DataDef, error)
+}
+
+// Writer writes the definition to the file, or standard output if the file is
+// empty.
+type Writer interface {
+	Write(data *DataDef, out string, opts Options) error
+}
+
+// ReaderFunc is an adapter to use the ordinary function as Reader.
+type ReaderFunc func(file string, opts Options) (*DataDef, error)
+
+func (f ReaderFunc) Read(file string, opts Options) (*DataDef, error) {
+	return f(file, opts)
+}
+
+// WriterFunc is an adapter to use the ordinary function as Writer.
+type WriterFunc func(data *DataDef, out string, opts Options) error
+
+func (f WriterFunc) Write(data *DataDef, out string, opts Options) error {
+	return f(data, out, opts)
+}
+
+// Format is a registered file format, either Reader or Writer can be nil.
+type Format struct {
+	Name    string   // unique name, e.g. yaml
+	Aliases []string // aliases of the name
+	Usage   string   // short description
+	Exts    []string // file extensions (lower case with the leading dot)
+	Options []string // names of the options used, e.g. OptTemplate
+	Reader  Reader
+	Writer  Writer
+}
+
+var formats = make([]Format, 0)
+
+// Register adds the format to the registry, it panics if the name is already
+// registered.
+func Register(f Format) {
+	if _, found := lo.Find(formats, func(r Format) bool { return r.Name == f.Name }); found {
+		panic("transform: format " + f.Name + " already registered")
+	}
+	formats = append(formats, f)
+}
+
+// Formats returns the registered formats sorted by name.
+func Formats() []Format {
+	result := append([]Format{}, formats...)
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// FormatByName returns the format by the name or alias.
+func FormatByName(name string) (*Format, error) {
+	f, found := lo.Find(formats, func(f Format) bool {
+		return strings.EqualFold(f.Name, name) || lo.Contains(f.Aliases, strings.ToLower(name))
+	})
+	if !found {
+		names := lo.Map(Formats(), func(f Format, _ int) string { return f.Name })
+		return nil, tracerr.Errorf("unknown format '%s', supported formats: %s", name, strings.Join(names, ", "))
+	}
+	return &f, nil
+}
+
+// FormatByExt returns the first registered format which can read (isRead is
+// true) or write the file extension.
+func FormatByExt(ext string, isRead bool) (*Format, error) {
+	ext = strings.ToLower(ext)
+	f, found := lo.Find(formats, func(f Format) bool {
+		return lo.Contains(f.Exts, ext) && (isRead && f.Reader != nil || !isRead && f.Writer != nil)
+	})
+	if !found {
+		kind := lo.Ternary(isRead, "input", "output")
+		return nil, tracerr.Errorf("unsupported %s format '%s', supported formats: %s", kind, ext, strings.Join(SupportedExts(isRead), ", "))
+	}
+	return &f, nil
+}
+
+// SupportedExts returns the file extensions supported by the readers (isRead
+// is true) or the writers.
+func SupportedExts(isRead bool) []string {
+	exts := make([]string, 0)
+	for _, f := range formats {
+		if isRead && f.Reader != nil || !isRead && f.Writer != nil {
+			exts = append(exts, f.Exts...)
+		}
+	}
+	exts = lo.Uniq(exts)
+	sort.Strings(exts)
+	return exts
+}
+
+// ReadFile reads the definition from the file, the reader is picked by the
+// file extension. A directory is read as the yaml files in it.
+func ReadFile(file string, opts Options) (*DataDef, error) {
+	if info, err := os.Stat(file); err == nil && info.IsDir() {
+		return ReadYml(file)
+	}
+	f, err := FormatByExt(filepath.Ext(file), true)
+	if err != nil {
+		return nil, err
+	}
+	return f.Reader.Read(file, opts)
+}
+
+// WriteFile writes the definition to the file, the writer is picked by the
+// file extension.
+func WriteFile(data *DataDef, out string, opts Options) error {
+	f, err := FormatByExt(filepath.Ext(out), false)
+	if err != nil {
+		return err
+	}
+	return f.Writer.Write(data, out, opts)
+}
@@ -0,0 +1,149 @@
+package transform
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+)
+
+func TestConvertByExt(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "s.yml")
+	writeTestFile(t, file, `schemas:
+  - name: app
+    tables:
+      - name: doc
+        desc: document
+        columns:
+          - { na: doc_id, ty: INT, id: Y, nu: Y }
+          - { na: title, ty: VARCHAR(100), dc: title of the document }
+`)
+	data, err := ReadFile(file, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	md := readTestOutput(t, "s.md", func(out string) error { return WriteFile(data, out, Options{}) })
+	assertInOrder(t, md, "doc", "document", "doc_id", "INT", "title", "VARCHAR(100)", "title of the document")
+
+	// the excel and yaml files are read back by the extension
+	dir := t.TempDir()
+	for _, name := range []string{"s.xlsx", "s.yaml"} {
+		out := filepath.Join(dir, name)
+		if err := WriteFile(data, out, Options{}); err != nil {
+			t.Fatal(err)
+		}
+		back, err := ReadFile(out, Options{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := back.Schemas[0].Tables[0].Columns; len(got) != 2 || got[1].DataType != "VARCHAR(100)" {
+			t.Errorf("%s: columns = %+v", name, got)
+		}
+	}
+}
+
+func TestConvertByExtErrors(t *testing.T) {
+	data := readTestYml(t, "schemas: []\n")
+	tests := []struct {
+		name string
+		err  func() error
+		want string
+	}{
+		{"output", func() error { return WriteFile(data, filepath.Join(t.TempDir(), "s.doc"), Options{}) },
+			"unsupported output format '.doc', supported formats: .md, .png, .puml, .sql, .svg, .txt, .xlsx, .yaml, .yml"},
+		{"input", func() error { _, err := ReadFile(filepath.Join(t.TempDir(), "s.md"), Options{}); return err },
+			"unsupported input format '.md', supported formats: .xlsx, .yaml, .yml"},
+		{"template", func() error { return WriteFile(data, filepath.Join(t.TempDir(), "s.sql"), Options{}) },
+			"template is required to output"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.err()
+			if err == nil || !strings.HasPrefix(tracerr.Unwrap(err).Error(), tt.want) {
+				t.Errorf("error = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFormatByName(t *testing.T) {
+	tests := []struct{ name, want string }{
+		{"yaml", "yaml"},
+		{"YML", "yaml"},
+		{"pg", "postgres"},
+		{"Excel", "excel"},
+		{"d", "diagram"},
+	}
+	for _, tt := range tests {
+		f, err := FormatByName(tt.name)
+		if err != nil {
+			t.Errorf("FormatByName(%q): %v", tt.name, err)
+		} else if f.Name != tt.want {
+			t.Errorf("FormatByName(%q) = %s, want %s", tt.name, f.Name, tt.want)
+		}
+	}
+	_, err := FormatByName("unknown")
+	want := "unknown format 'unknown', supported formats: diagram, excel, markdown, postgres, sqlite, text, yaml"
+	if err == nil || tracerr.Unwrap(err).Error() != want {
+		t.Errorf("error = %v, want %s", err, want)
+	}
+}
+
+func TestFormatByExt(t *testing.T) {
+	tests := []struct {
+		ext    string
+		isRead bool
+		want   string
+	}{
+		{".yml", true, "yaml"},
+		{".YAML", false, "yaml"},
+		{".xlsx", true, "excel"},
+		{".svg", false, "diagram"},
+		{".sql", false, "text"},
+		{".md", false, "markdown"},
+	}
+	for _, tt := range tests {
+		f, err := FormatByExt(tt.ext, tt.isRead)
+		if err != nil {
+			t.Errorf("FormatByExt(%q, %v): %v", tt.ext, tt.isRead, err)
+		} else if f.Name != tt.want {
+			t.Errorf("FormatByExt(%q, %v) = %s, want %s", tt.ext, tt.isRead, f.Name, tt.want)
+		}
+	}
+}
+
+func TestRegister(t *testing.T) {
+	saved := formats
+	t.Cleanup(func() { formats = saved })
+	formats = append([]Format{}, saved...)
+
+	// a new format is read and written by the extension through the adapters
+	var written *DataDef
+	Register(Format{
+		Name:   "test",
+		Exts:   []string{".test"},
+		Reader: ReaderFunc(func(file string, opts Options) (*DataDef, error) { return &DataDef{Fixed: []Column{{Name: file}}}, nil }),
+		Writer: WriterFunc(func(data *DataDef, out string, opts Options) error { written = data; return nil }),
+	})
+	data, err := ReadFile("s.test", Options{})
+	if err != nil || data.Fixed[0].Name != "s.test" {
+		t.Fatalf("ReadFile = %+v, %v", data, err)
+	}
+	if err := WriteFile(data, "out.TEST", Options{}); err != nil || written != data {
+		t.Errorf("WriteFile = %v, written %+v", err, written)
+	}
+	names := strings.Join(lo.Map(Formats(), func(f Format, _ int) string { return f.Name }), ",")
+	if want := "diagram,excel,markdown,postgres,sqlite,test,text,yaml"; names != want {
+		t.Errorf("formats = %s, want %s", names, want)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a panic for the registered name")
+		}
+	}()
+	Register(Format{Name: "yaml"})
+}
@@ -0,0 +1,191 @@
+package transform
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+)
+
+// git runs the git command in the directory and returns the output.
+func git(dir string, args ...string) ([]byte, error) {
+	return gitInput(dir, nil, args...)
+}
+
+// gitInput runs the git command in the directory with the input and returns
+// the output.
+func gitInput(dir string, input io.Reader, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdin = input
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		return nil, tracerr.Errorf("git %s: %s", strings.Join(args, " "), lo.Ternary(msg != "", msg, err.Error()))
+	}
+	return out, nil
+}
+
+// gitBlob is a file of the tree of a commit.
+type gitBlob struct {
+	Object string // object name of the content
+	Path   string // path relative to the root of the repository
+}
+
+// ReadRevision reads the definition file (or directory) at the revision of
+// the local git repository, e.g. main, HEAD~1 or a commit hash. The file and
+// the yaml files of the revision (which may be included) are extracted to a
+// temporary directory, so the included files are read at the same revision.
+// The positions refer to the files in the working tree, e.g. the file as
+// given.
+func ReadRevision(file string, rev string, opts Options) (*DataDef, error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	// the path of the file relative to the root of the repository
+	dir, base := abs, ""
+	if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+		dir, base = filepath.Dir(abs), filepath.Base(abs)
+	}
+	out, err := git(dir, "rev-parse", "--show-toplevel", "--show-prefix")
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimRight(string(out), "\n"), "\n")
+	root, rel := lines[0], ""
+	if len(lines) > 1 {
+		rel = lines[1]
+	}
+	rel = filepath.Join(filepath.FromSlash(rel), base, ".")
+
+	// the revision is resolved first, so it is not taken as an option
+	out, err = git(root, "rev-parse", "--verify", "--quiet", "--end-of-options", rev+"^{commit}")
+	if err != nil {
+		return nil, tracerr.Errorf("revision '%s' cannot be found", rev)
+	}
+	commit := strings.TrimSpace(string(out))
+
+	target := filepath.ToSlash(rel)
+	blobs, err := gitBlobs(root, commit)
+	if err != nil {
+		return nil, err
+	}
+	inTarget := func(b gitBlob) bool {
+		return target == "." || b.Path == target || strings.HasPrefix(b.Path, target+"/")
+	}
+	if !lo.ContainsBy(blobs, inTarget) {
+		return nil, tracerr.Errorf("file '%s' does not exist at revision '%s'", target, rev)
+	}
+	// only the file and the yaml files are extracted, not the whole repository
+	blobs = lo.Filter(blobs, func(b gitBlob, _ int) bool {
+		ext := strings.ToLower(path.Ext(b.Path))
+		return b.Path == target || ext == ".yml" || ext == ".yaml"
+	})
+
+	tmp, err := os.MkdirTemp("", "dst-rev-")
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	defer os.RemoveAll(tmp)
+	if err := extractBlobs(root, blobs, tmp); err != nil {
+		return nil, err
+	}
+
+	target = filepath.Join(tmp, rel)
+	data, err := ReadFile(target, opts)
+	if err != nil {
+		return nil, err
+	}
+	mapSourceFiles(data, func(f string) string { return revisionFile(f, tmp, target, file, root) })
+	return data, nil
+}
+
+// revisionFile returns the file in the working tree of the file extracted to
+// the temporary directory, the target (the file or directory read) is mapped
+// to the file as given and the other files to the root of the repository.
+func revisionFile(f string, tmp string, target string, file string, root string) string {
+	if f == target {
+		return file
+	}
+	if rel, err := filepath.Rel(target, f); err == nil && !isOutside(rel) {
+		return filepath.Join(file, rel)
+	}
+	if rel, err := filepath.Rel(tmp, f); err == nil && !isOutside(rel) {
+		return filepath.Join(root, rel)
+	}
+	return f
+}
+
+// gitBlobs returns the regular files of the tree of the commit, the symbolic
+// links and submodules are excluded.
+func gitBlobs(root string, commit string) ([]gitBlob, error) {
+	out, err := git(root, "ls-tree", "-r", "-z", "--full-tree", commit)
+	if err != nil {
+		return nil, err
+	}
+	blobs := make([]gitBlob, 0)
+	for _, entry := range strings.Split(strings.TrimSuffix(string(out), "\x00"), "\x00") {
+		// <mode> SP <type> SP <object> TAB <path>
+		info, name, found := strings.Cut(entry, "\t")
+		fields := strings.Fields(info)
+		if found && len(fields) == 3 && fields[1] == "blob" && fields[0] != "120000" {
+			blobs = append(blobs, gitBlob{Object: fields[2], Path: name})
+		}
+	}
+	return blobs, nil
+}
+
+// extractBlobs writes the contents of the files read by git cat-file --batch
+// to the directory.
+func extractBlobs(root string, blobs []gitBlob, dir string) error {
+	var input bytes.Buffer
+	for _, b := range blobs {
+		input.WriteString(b.Object + "\n")
+	}
+	out, err := gitInput(root, &input, "cat-file", "--batch")
+	if err != nil {
+		return err
+	}
+	r := bufio.NewReader(bytes.NewReader(out))
+	for _, b := range blobs {
+		// <object> SP <type> SP <size> LF <contents> LF
+		header, err := r.ReadString('\n')
+		if err != nil {
+			return tracerr.Wrap(err)
+		}
+		fields := strings.Fields(header)
+		if len(fields) != 3 {
+			return tracerr.Errorf("file '%s' cannot be read: %s", b.Path, strings.TrimSpace(header))
+		}
+		size, err := strconv.Atoi(fields[2])
+		if err != nil {
+			return tracerr.Wrap(err)
+		}
+		content := make([]byte, size+1)
+		if _, err := io.ReadFull(r, content); err != nil {
+			return tracerr.Wrap(err)
+		}
+		name := filepath.Join(dir, filepath.FromSlash(b.Path))
+		if rel, err := filepath.Rel(dir, name); err != nil || isOutside(rel) {
+			return tracerr.Errorf("invalid file '%s' in the revision", b.Path)
+		}
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return tracerr.Wrap(err)
+		}
+		if err := os.WriteFile(name, content[:size], 0o644); err != nil {
+			return tracerr.Wrap(err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package transform
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gitRepo creates a git repository with the files committed.
+func gitRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		writeTestFile(t, filepath.Join(root, name), content)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"-c", "user.name=dst", "-c", "user.email=dst@example.com", "commit", "-q", "-m", "init"},
+	} {
+		if _, err := git(root, args...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestReadRevisionPositions(t *testing.T) {
+	root := gitRepo(t, map[string]string{
+		"defs/s.yml": `include: [../common.yml]
+schemas:
+  - name: app
+    tables:
+      - name: doc
+        columns:
+          - { na: doc_id, ty: INT, id: Y }
+`,
+		"common.yml": `fixed:
+  - { na: created, ty: DATETIME }
+`,
+	})
+	file := filepath.Join(root, "defs", "s.yml")
+	// the working tree differs from the revision
+	writeTestFile(t, file, "schemas: []\n")
+
+	data, err := ReadRevision(file, "HEAD", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data.Schemas) != 1 || len(data.Schemas[0].Tables) != 1 {
+		t.Fatalf("unexpected schemas %+v", data.Schemas)
+	}
+	table := data.Schemas[0].Tables[0]
+	if want := (Position{File: file, Line: 5, Col: 9}); table.Pos != want {
+		t.Errorf("table position = %v, want %v", table.Pos, want)
+	}
+	if want := (Position{File: file, Line: 7, Col: 13}); table.Columns[0].Pos != want {
+		t.Errorf("column position = %v, want %v", table.Columns[0].Pos, want)
+	}
+	if want := (Position{File: filepath.Join(root, "common.yml"), Line: 2, Col: 5}); data.Fixed[0].Pos != want {
+		t.Errorf("fixed column position = %v, want %v", data.Fixed[0].Pos, want)
+	}
+	if _, err := os.Stat(data.Fixed[0].Pos.File); err != nil {
+		t.Errorf("reported file does not exist: %v", err)
+	}
+}
+
+func TestReadRevisionDirectory(t *testing.T) {
+	root := gitRepo(t, map[string]string{
+		"defs/a.yml": "schemas:\n  - name: app\n    tables:\n      - name: a\n",
+		"defs/b.yml": "schemas:\n  - name: app\n    tables:\n      - name: b\n",
+	})
+	dir := filepath.Join(root, "defs")
+	data, err := ReadRevision(dir, "HEAD", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, name := range []string{"a.yml", "b.yml"} {
+		if got, want := data.Schemas[0].Tables[i].Pos.File, filepath.Join(dir, name); got != want {
+			t.Errorf("table %d file = %s, want %s", i, got, want)
+		}
+	}
+}
+
+func TestReadRevisionMissingFile(t *testing.T) {
+	root := gitRepo(t, map[string]string{"s.yml": "schemas: []\n"})
+	writeTestFile(t, filepath.Join(root, "new.yml"), "schemas: []\n")
+	if _, err := ReadRevision(filepath.Join(root, "new.yml"), "HEAD", Options{}); err == nil {
+		t.Error("expected an error for the file not at the revision")
+	}
+}
+
+func TestReadRevisionRoot(t *testing.T) {
+	root := gitRepo(t, map[string]string{
+		"a.yml":      "schemas:\n  - name: app\n    tables:\n      - name: a\n",
+		"doc/b.yaml": "schemas:\n  - name: app\n    tables:\n      - name: b\n",
+		"README.md":  "# not a definition\n",
+	})
+	data, err := ReadRevision(root, "HEAD", Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tableNames(SortTables(data)); strings.Join(got, ",") != "a,b" {
+		t.Errorf("tables = %v, want [a b]", got)
+	}
+}
+
+func TestReadRevisionInvalid(t *testing.T) {
+	root := gitRepo(t, map[string]string{"s.yml": "schemas: []\n"})
+	out := filepath.Join(t.TempDir(), "out")
+	for _, rev := range []string{"--output=" + out, "unknown", "HEAD:s.yml"} {
+		if _, err := ReadRevision(filepath.Join(root, "s.yml"), rev, Options{}); err == nil {
+			t.Errorf("expected an error for the revision '%s'", rev)
+		}
+	}
+	if _, err := os.Stat(out); err == nil {
+		t.Error("the revision is taken as an option")
+	}
+}
@@ -0,0 +1,202 @@
+package transform
+
+import (
+	"sort"
+
+	"github.com/samber/lo"
+)
+
+// TableRef is a table with the name of its schema.
+type TableRef struct {
+	Schema string
+	Table  Table
+}
+
+// tableDeps returns the names of the tables referenced by the foreign keys of
+// the table, the self reference and the tables not in the definition are
+// excluded.
+func tableDeps(data *DataDef, table Table, exists map[string]bool) []string {
+	deps := make([]string, 0)
+	for _, fk := range data.ForeignKeys(table) {
+		if fk.RefTable != table.Name && exists[fk.RefTable] && !lo.Contains(deps, fk.RefTable) {
+			deps = append(deps, fk.RefTable)
+		}
+	}
+	return deps
+}
+
+// tableGraph returns the tables in the definition order and the dependencies
+// of the tables by name.
+func tableGraph(data *DataDef) ([]TableRef, map[string][]string) {
+	refs := make([]TableRef, 0)
+	exists := make(map[string]bool)
+	for _, schema := range data.Schemas {
+		for _, table := range schema.Tables {
+			refs = append(refs, TableRef{Schema: schema.Name, Table: table})
+			exists[table.Name] = true
+		}
+	}
+	deps := make(map[string][]string)
+	for _, ref := range refs {
+		deps[ref.Table.Name] = tableDeps(data, ref.Table, exists)
+	}
+	return refs, deps
+}
+
+// SortTables returns the tables in the order that the referenced tables come
+// before the referencing tables, the tables without dependency keep the order
+// in the definition. A cycle (see TableCycles) is broken at its first table
+// in the definition order when the other tables referenced by the cycle are
+// sorted. The tables are dropped in the reverse order.
+func SortTables(data *DataDef) []TableRef {
+	refs, deps := tableGraph(data)
+	cycle := make(map[string][]string)
+	for _, component := range tableComponents(refs, deps) {
+		for _, name := range component {
+			cycle[name] = component
+		}
+	}
+	// ready returns true if the table is in a cycle and the tables referenced
+	// by the cycle are done
+	ready := func(name string, done map[string]bool) bool {
+		members := cycle[name]
+		return len(members) > 0 && lo.EveryBy(members, func(member string) bool {
+			return lo.EveryBy(deps[member], func(dep string) bool { return done[dep] || lo.Contains(members, dep) })
+		})
+	}
+
+	// the tables are added by the index, the names may be duplicate (reported by Verify)
+	result := make([]TableRef, 0, len(refs))
+	added := make([]bool, len(refs))
+	done := make(map[string]bool)
+	add := func(i int) {
+		result = append(result, refs[i])
+		added[i] = true
+		done[refs[i].Table.Name] = true
+	}
+	for len(result) < len(refs) {
+		progress := false
+		for i, ref := range refs {
+			if !added[i] && lo.EveryBy(deps[ref.Table.Name], func(dep string) bool { return done[dep] }) {
+				add(i)
+				progress = true
+			}
+		}
+		if progress {
+			continue
+		}
+		// the remaining tables depend on a cycle, break the cycle, or take the
+		// first remaining table if no cycle is ready
+		i := lo.IndexOf(added, false)
+		for j, ref := range refs {
+			if !added[j] && ready(ref.Table.Name, done) {
+				i = j
+				break
+			}
+		}
+		add(i)
+	}
+	return result
+}
+
+// ReverseTables returns the tables in the reverse order, e.g. the order to
+// drop the tables of SortTables.
+func ReverseTables(tables []TableRef) []TableRef {
+	return lo.Reverse(append([]TableRef{}, tables...))
+}
+
+// TableCycles returns the cycles of the foreign keys between the tables, one
+// cycle for each group of the tables referencing each other. A cycle is the
+// path of the table names from its first table in the definition order back
+// to the table, e.g. [a b c a]. The self references are not cycles.
+func TableCycles(data *DataDef) [][]string {
+	refs, deps := tableGraph(data)
+	order := make(map[string]int)
+	for i, ref := range refs {
+		order[ref.Table.Name] = i
+	}
+
+	components := tableComponents(refs, deps)
+	cycles := make([][]string, 0, len(components))
+	for _, component := range components {
+		start := lo.MinBy(component, func(a, b string) bool { return order[a] < order[b] })
+		cycles = append(cycles, cyclePath(start, component, deps))
+	}
+	sort.Slice(cycles, func(i, j int) bool { return order[cycles[i][0]] < order[cycles[j][0]] })
+	return cycles
+}
+
+// tableComponents returns the groups of the tables referencing each other,
+// which are the strongly connected components of more than one table found by
+// Tarjan's algorithm.
+func tableComponents(refs []TableRef, deps map[string][]string) [][]string {
+	index, lowlink := make(map[string]int), make(map[string]int)
+	onStack := make(map[string]bool)
+	stack := make([]string, 0)
+	components := make([][]string, 0)
+	var connect func(name string)
+	connect = func(name string) {
+		index[name], lowlink[name] = len(index), len(index)
+		stack = append(stack, name)
+		onStack[name] = true
+		for _, dep := range deps[name] {
+			if _, visited := index[dep]; !visited {
+				connect(dep)
+				if lowlink[dep] < lowlink[name] {
+					lowlink[name] = lowlink[dep]
+				}
+			} else if onStack[dep] && index[dep] < lowlink[name] {
+				lowlink[name] = index[dep]
+			}
+		}
+		if lowlink[name] == index[name] {
+			component := make([]string, 0)
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == name {
+					break
+				}
+			}
+			if len(component) > 1 {
+				components = append(components, component)
+			}
+		}
+	}
+	for _, ref := range refs {
+		if _, visited := index[ref.Table.Name]; !visited {
+			connect(ref.Table.Name)
+		}
+	}
+	return components
+}
+
+// cyclePath returns the shortest path from the start table back to itself in
+// the component by the breadth first search.
+func cyclePath(start string, component []string, deps map[string][]string) []string {
+	prev := make(map[string]string)
+	queue := []string{start}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, dep := range deps[name] {
+			if !lo.Contains(component, dep) {
+				continue
+			}
+			if dep == start {
+				path := make([]string, 0)
+				for n := name; n != start; n = prev[n] {
+					path = append(path, n)
+				}
+				return append(append([]string{start}, lo.Reverse(path)...), start)
+			}
+			if _, found := prev[dep]; !found {
+				prev[dep] = name
+				queue = append(queue, dep)
+			}
+		}
+	}
+	return nil
+}
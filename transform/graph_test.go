@@ -0,0 +1,102 @@
+package transform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+// graphTestData returns the definition of the tables, each table is
+// "name:ref1,ref2" with the foreign keys to the referenced tables.
+func graphTestData(t *testing.T, tables ...string) *DataDef {
+	t.Helper()
+	var sb strings.Builder
+	sb.WriteString("schemas:\n  - name: app\n    tables:\n")
+	for _, table := range tables {
+		name, refs, _ := strings.Cut(table, ":")
+		sb.WriteString("      - name: " + name + "\n        columns:\n")
+		sb.WriteString("          - { na: " + name + "_id, ty: INT, id: Y, nu: Y }\n")
+		for _, ref := range lo.Compact(strings.Split(refs, ",")) {
+			sb.WriteString("          - { na: " + ref + "_ref, ty: INT, fk: " + ref + "." + ref + "_id }\n")
+		}
+	}
+	return readTestYml(t, sb.String())
+}
+
+func tableNames(refs []TableRef) []string {
+	return lo.Map(refs, func(ref TableRef, _ int) string { return ref.Table.Name })
+}
+
+func TestSortTables(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables []string
+		want   []string
+	}{
+		{"acyclic", []string{"note:doc,tag", "doc_tag:doc,tag", "doc", "tag"}, []string{"doc", "tag", "note", "doc_tag"}},
+		{"definition order", []string{"b", "a", "c:b"}, []string{"b", "a", "c"}},
+		{"self reference", []string{"node:node,tree", "tree"}, []string{"tree", "node"}},
+		// the dependent table is defined first, the cycle is broken at its first table
+		{"2-cycle with dependent", []string{"c:a", "a:b", "b:a"}, []string{"a", "c", "b"}},
+		{"2-cycle depends on table", []string{"a:b,x", "b:a", "x"}, []string{"x", "a", "b"}},
+		{"dependent cycles", []string{"c:d", "d:c,a", "a:b", "b:a"}, []string{"a", "b", "c", "d"}},
+		// the duplicate table names are reported by Verify, the tables are kept
+		{"duplicate table", []string{"a", "b:a", "a"}, []string{"a", "b", "a"}},
+		{"duplicate table in cycle", []string{"a:b", "b:a", "a:b"}, []string{"a", "b", "a"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sorted := SortTables(graphTestData(t, tt.tables...))
+			if got := tableNames(sorted); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SortTables() = %v, want %v", got, tt.want)
+			}
+			if got, want := tableNames(ReverseTables(sorted)), lo.Reverse(append([]string{}, tt.want...)); !reflect.DeepEqual(got, want) {
+				t.Errorf("ReverseTables() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestTableCycles(t *testing.T) {
+	tests := []struct {
+		name   string
+		tables []string
+		want   [][]string
+	}{
+		{"acyclic", []string{"a:b", "b"}, [][]string{}},
+		{"self reference", []string{"a:a"}, [][]string{}},
+		{"2-cycle", []string{"c:a", "b:a", "a:b"}, [][]string{{"b", "a", "b"}}},
+		// the shortest path from the first table, not the longer a-b-c-a
+		{"shortest path", []string{"a:b,c", "b:c", "c:a"}, [][]string{{"a", "c", "a"}}},
+		{"3-cycle", []string{"x", "a:b", "b:c", "c:a,x"}, [][]string{{"a", "b", "c", "a"}}},
+		{"cycles in definition order", []string{"p:q", "a:b", "q:p", "b:a"}, [][]string{{"p", "q", "p"}, {"a", "b", "a"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TableCycles(graphTestData(t, tt.tables...)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TableCycles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyCycles(t *testing.T) {
+	data := graphTestData(t, "c:a", "a:b", "b:a")
+	findings := lo.Filter(Verify(data, LintConfig{}), func(f Finding, _ int) bool { return f.Rule == RuleFKCycle })
+	if len(findings) != 1 {
+		t.Fatalf("cycle findings = %v, want 1", findings)
+	}
+	f := findings[0]
+	if f.Severity != SeverityWarning || f.Table != "a" || f.Column != "b_ref" || f.Pos.Line != 11 {
+		t.Errorf("finding = %+v", f)
+	}
+	if !strings.Contains(f.Message, "foreign key cycle a -> b -> a") {
+		t.Errorf("message = %s", f.Message)
+	}
+	// the cycle is not a lint finding, the conversions report it as well
+	if !lo.ContainsBy(VerifyStructure(data), func(f Finding) bool { return f.Rule == RuleFKCycle }) {
+		t.Error("cycle is not reported by VerifyStructure")
+	}
+}
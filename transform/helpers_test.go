@@ -0,0 +1,60 @@
+package transform
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, file string, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// readTestYml reads the definition of the yaml content from a temporary file
+// named s.yml.
+func readTestYml(t *testing.T, content string) *DataDef {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "s.yml")
+	writeTestFile(t, file, content)
+	data, err := ReadYml(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+// readTestOutput returns the output written by the write function to a
+// temporary file.
+func readTestOutput(t *testing.T, name string, write func(out string) error) string {
+	t.Helper()
+	out := filepath.Join(t.TempDir(), name)
+	if err := write(out); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// assertInOrder checks the text contains the parts in order.
+func assertInOrder(t *testing.T, text string, parts ...string) {
+	t.Helper()
+	rest := text
+	for _, part := range parts {
+		i := strings.Index(rest, part)
+		if i < 0 {
+			t.Errorf("%q not found in order in:\n%s", part, text)
+			return
+		}
+		rest = rest[i+len(part):]
+	}
+}
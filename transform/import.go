@@ -0,0 +1,221 @@
+package transform
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+)
+
+// RuleImport is the rule of the findings reported by the importers, e.g. an
+// object which cannot be described by the definition.
+const RuleImport = "import"
+
+// importWarning returns the warning of the object not imported.
+func importWarning(schema string, table string, message string) Finding {
+	return Finding{Rule: RuleImport, Severity: SeverityWarning, Schema: schema, Table: table, Message: message}
+}
+
+// importType returns the data type of the database in upper case, the raw
+// type is not taken as a logical type of the same name, e.g. text.
+func importType(dataType string) string {
+	return strings.ToUpper(dataType)
+}
+
+// findColumn returns the column of the table by name, or nil if not found.
+func findColumn(table *Table, name string) *Column {
+	for i := range table.Columns {
+		if table.Columns[i].Name == name {
+			return &table.Columns[i]
+		}
+	}
+	return nil
+}
+
+// importPrimaryKey sets the primary key of the table, it is omitted if the
+// columns are the identity columns of the table (see PrimaryKeyColumns).
+func importPrimaryKey(table *Table, pk []string) {
+	if len(pk) == 0 {
+		return
+	}
+	identity := lo.FilterMap(table.Columns, func(c Column, _ int) (string, bool) { return c.Name, isYes(c.Identity) })
+	if strings.Join(identity, ",") != strings.Join(pk, ",") {
+		table.PrimaryKey = pk
+	}
+	for _, name := range pk {
+		if column := findColumn(table, name); column != nil {
+			column.NotNull = "Y"
+		}
+	}
+}
+
+// importForeignKey adds the foreign key to the table, the single column
+// foreign key of the default name and without the actions is the fk hint of
+// the column. The default name is omitted.
+func importForeignKey(table *Table, fk ForeignKey) {
+	if fk.Name == fkName(table.Name, ForeignKey{Columns: fk.Columns}) {
+		fk.Name = ""
+	}
+	if fk.Name == "" && len(fk.Columns) == 1 && len(fk.RefColumns) == 1 && fk.OnDelete == "" && fk.OnUpdate == "" {
+		if column := findColumn(table, fk.Columns[0]); column != nil && column.ForeignKey == "" {
+			column.ForeignKey = fk.RefTable + "." + fk.RefColumns[0]
+			return
+		}
+	}
+	table.ForeignKeys = append(table.ForeignKeys, fk)
+}
+
+// importUnique adds the unique constraint to the table, the single column
+// constraint is the unique of the column, otherwise it is a unique index.
+func importUnique(table *Table, name string, columns []string) {
+	if len(columns) == 1 {
+		if column := findColumn(table, columns[0]); column != nil {
+			column.Unique = "Y"
+			return
+		}
+	}
+	importIndex(table, Index{Name: name, Columns: columns, Unique: true})
+}
+
+// importIndex adds the index to the table, the default name is omitted.
+func importIndex(table *Table, ix Index) {
+	if ix.Name == indexName(table.Name, Index{Columns: ix.Columns}) {
+		ix.Name = ""
+	}
+	table.Indexes = append(table.Indexes, ix)
+}
+
+// enumCheckRegexp matches the check of the allowed values, e.g. source IN ('COM', 'PRE')
+var enumCheckRegexp = regexp.MustCompile("(?is)^[\"`\\[(]*([A-Za-z0-9_]+)[\"`\\])]*(?:::[a-z ]+)?\\s+IN\\s*\\((.*)\\)$")
+
+// importCheck adds the check to the table, the check of the allowed values
+// named ck_<table>_<column> (see tableChecks) is the enum of the column. The
+// default name ck_<table>_<n> is omitted.
+func importCheck(table *Table, check Check) {
+	check.Expr = trimParens(check.Expr)
+	if m := enumCheckRegexp.FindStringSubmatch(check.Expr); m != nil && strings.EqualFold(check.Name, "ck_"+table.Name+"_"+m[1]) {
+		if column := findColumn(table, m[1]); column != nil && len(column.Enum) == 0 {
+			if values, ok := splitValues(m[2]); ok {
+				column.Enum = lo.Map(values, func(v string, _ int) EnumValue { return EnumValue{Value: v} })
+				return
+			}
+		}
+	}
+	if check.Name == fmt.Sprintf("ck_%s_%d", table.Name, len(table.Checks)+1) {
+		check.Name = ""
+	}
+	table.Checks = append(table.Checks, check)
+}
+
+// splitValues returns the values of the comma separated list of the string
+// literals and numbers, ok is false if any item is not a literal.
+func splitValues(list string) ([]string, bool) {
+	values := make([]string, 0)
+	for _, item := range splitOutside(list, ',') {
+		item = strings.TrimSpace(item)
+		switch {
+		case len(item) > 1 && item[0] == '\'' && item[len(item)-1] == '\'':
+			values = append(values, strings.ReplaceAll(item[1:len(item)-1], "''", "'"))
+		case numberRegexp.MatchString(item):
+			values = append(values, item)
+		default:
+			return nil, false
+		}
+	}
+	return values, len(values) > 0
+}
+
+// splitOutside splits the text by the separator outside the string literals
+// and parentheses.
+func splitOutside(text string, sep rune) []string {
+	parts := make([]string, 0)
+	depth, quoted, start := 0, false, 0
+	for i, r := range text {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == sep && depth == 0:
+			parts = append(parts, text[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, text[start:])
+}
+
+// importAction returns the action of the foreign key, NO ACTION (the default)
+// is empty.
+func importAction(action string) string {
+	return lo.Ternary(strings.EqualFold(action, "NO ACTION"), "", strings.ToUpper(action))
+}
+
+// queryRows runs the query and calls scan for each row.
+func queryRows(db *sql.DB, query string, args []interface{}, scan func(rows *sql.Rows) error) error {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		if err := scan(rows); err != nil {
+			return tracerr.Wrap(err)
+		}
+	}
+	return tracerr.Wrap(rows.Err())
+}
+
+// importDefault returns the default value of the column from the SQL
+// expression of the database, the outer parentheses are removed and the
+// string literal is unquoted if sqlDefault quotes it back, e.g. 'abc' is abc
+// but '0' is kept. NULL is no default.
+func importDefault(expr string) string {
+	v := trimParens(expr)
+	if strings.EqualFold(v, "NULL") {
+		return ""
+	}
+	if len(v) > 1 && v[0] == '\'' && v[len(v)-1] == '\'' {
+		if s := strings.ReplaceAll(v[1:len(v)-1], "''", "'"); sqlDefault(s) == v {
+			return s
+		}
+	}
+	return v
+}
+
+// trimParens returns the expression without the outer parentheses, e.g.
+// ((deleted = 0)) is deleted = 0.
+func trimParens(expr string) string {
+	v := strings.TrimSpace(expr)
+	for len(v) > 1 && v[0] == '(' && closingParen(v) == len(v)-1 {
+		v = strings.TrimSpace(v[1 : len(v)-1])
+	}
+	return v
+}
+
+// closingParen returns the index of the parenthesis closing the first one of
+// the expression, the parentheses in the string literals are skipped. It
+// returns -1 if not closed.
+func closingParen(expr string) int {
+	depth, quoted := 0, false
+	for i, r := range expr {
+		switch {
+		case r == '\'':
+			quoted = !quoted
+		case quoted:
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
@@ -0,0 +1,156 @@
+package transform
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImportDefault(t *testing.T) {
+	tests := []struct{ expr, want string }{
+		{"", ""},
+		{"NULL", ""},
+		{"(NULL)", ""},
+		{"0", "0"},
+		{"((0))", "0"},
+		{"'abc'", "abc"},
+		{"'it''s'", "it's"},
+		// the quoted number and keyword are kept quoted, sqlDefault would not quote them back
+		{"'0'", "'0'"},
+		{"'NULL'", "'NULL'"},
+		{"CURRENT_TIMESTAMP", "CURRENT_TIMESTAMP"},
+		{"(getdate())", "getdate()"},
+		{"('a')", "a"},
+	}
+	for _, tt := range tests {
+		if got := importDefault(tt.expr); got != tt.want {
+			t.Errorf("importDefault(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestTrimParens(t *testing.T) {
+	tests := []struct{ expr, want string }{
+		{"deleted = 0", "deleted = 0"},
+		{" ((deleted = 0)) ", "deleted = 0"},
+		{"(a) AND (b)", "(a) AND (b)"},
+		{"(a = ')')", "a = ')'"},
+		{"(a", "(a"},
+		{"()", ""},
+	}
+	for _, tt := range tests {
+		if got := trimParens(tt.expr); got != tt.want {
+			t.Errorf("trimParens(%q) = %q, want %q", tt.expr, got, tt.want)
+		}
+	}
+}
+
+func TestSplitValues(t *testing.T) {
+	tests := []struct {
+		list   string
+		values []string
+		ok     bool
+	}{
+		{"'A', 'B'", []string{"A", "B"}, true},
+		{"'a,b', 'it''s'", []string{"a,b", "it's"}, true},
+		{"1, -2, 3.5", []string{"1", "-2", "3.5"}, true},
+		{"'A', upper('b')", nil, false},
+		{"A", nil, false},
+		{"", nil, false},
+	}
+	for _, tt := range tests {
+		values, ok := splitValues(tt.list)
+		if ok != tt.ok || (ok && !reflect.DeepEqual(values, tt.values)) {
+			t.Errorf("splitValues(%q) = %q, %v, want %q, %v", tt.list, values, ok, tt.values, tt.ok)
+		}
+	}
+}
+
+func TestImportCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		check  Check
+		enum   Enum    // the enum of the column kind
+		checks []Check // the checks of the table
+	}{
+		{"enum", Check{Name: "ck_doc_kind", Expr: "((kind IN ('A', 'B')))"},
+			Enum{{Value: "A"}, {Value: "B"}}, nil},
+		{"enum of quoted column with cast", Check{Name: "ck_doc_kind", Expr: `"kind"::text IN ('A', 'B')`},
+			Enum{{Value: "A"}, {Value: "B"}}, nil},
+		// the check of other name is not the enum of the column
+		{"named check", Check{Name: "ck_kind", Expr: "kind IN ('A', 'B')"},
+			nil, []Check{{Name: "ck_kind", Expr: "kind IN ('A', 'B')"}}},
+		{"not literals", Check{Name: "ck_doc_kind", Expr: "kind IN (upper(kind))"},
+			nil, []Check{{Name: "ck_doc_kind", Expr: "kind IN (upper(kind))"}}},
+		{"default name", Check{Name: "ck_doc_1", Expr: "(ver >= 0)"},
+			nil, []Check{{Expr: "ver >= 0"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := Table{Name: "doc", Columns: []Column{{Name: "kind"}}}
+			importCheck(&table, tt.check)
+			if got := table.Columns[0].Enum; !reflect.DeepEqual(got, tt.enum) {
+				t.Errorf("enum = %v, want %v", table.Columns[0].Enum, tt.enum)
+			}
+			if !reflect.DeepEqual(table.Checks, tt.checks) {
+				t.Errorf("checks = %v, want %v", table.Checks, tt.checks)
+			}
+		})
+	}
+}
+
+func TestImportForeignKey(t *testing.T) {
+	tests := []struct {
+		name string
+		fk   ForeignKey
+		hint string       // the fk hint of the column ref
+		fks  []ForeignKey // the foreign keys of the table
+	}{
+		{"hint", ForeignKey{Name: "fk_doc_ref", Columns: []string{"ref"}, RefTable: "tag", RefColumns: []string{"tag_id"}},
+			"tag.tag_id", nil},
+		{"named", ForeignKey{Name: "fk_ref", Columns: []string{"ref"}, RefTable: "tag", RefColumns: []string{"tag_id"}},
+			"", []ForeignKey{{Name: "fk_ref", Columns: []string{"ref"}, RefTable: "tag", RefColumns: []string{"tag_id"}}}},
+		{"actions", ForeignKey{Name: "fk_doc_ref", Columns: []string{"ref"}, RefTable: "tag", RefColumns: []string{"tag_id"}, OnDelete: "CASCADE"},
+			"", []ForeignKey{{Columns: []string{"ref"}, RefTable: "tag", RefColumns: []string{"tag_id"}, OnDelete: "CASCADE"}}},
+		{"composite", ForeignKey{Name: "fk_doc_ref_ver", Columns: []string{"ref", "ver"}, RefTable: "tag", RefColumns: []string{"tag_id", "ver"}},
+			"", []ForeignKey{{Columns: []string{"ref", "ver"}, RefTable: "tag", RefColumns: []string{"tag_id", "ver"}}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := Table{Name: "doc", Columns: []Column{{Name: "ref"}, {Name: "ver"}}}
+			importForeignKey(&table, tt.fk)
+			if got := table.Columns[0].ForeignKey; got != tt.hint {
+				t.Errorf("fk hint = %q, want %q", got, tt.hint)
+			}
+			if !reflect.DeepEqual(table.ForeignKeys, tt.fks) {
+				t.Errorf("foreign keys = %+v, want %+v", table.ForeignKeys, tt.fks)
+			}
+		})
+	}
+}
+
+func TestImportPrimaryKey(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity string // the identity of the column id
+		pk       []string
+		want     []string // the primary key of the table
+		notNull  []string // the not null of the columns id and ver
+	}{
+		{"identity", "Y", []string{"id"}, nil, []string{"Y", ""}},
+		{"not identity", "", []string{"id"}, []string{"id"}, []string{"Y", ""}},
+		{"composite", "Y", []string{"id", "ver"}, []string{"id", "ver"}, []string{"Y", "Y"}},
+		{"none", "", nil, nil, []string{"", ""}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table := Table{Name: "doc", Columns: []Column{{Name: "id", Identity: tt.identity}, {Name: "ver"}}}
+			importPrimaryKey(&table, tt.pk)
+			if !reflect.DeepEqual(table.PrimaryKey, tt.want) {
+				t.Errorf("primary key = %v, want %v", table.PrimaryKey, tt.want)
+			}
+			if got := []string{table.Columns[0].NotNull, table.Columns[1].NotNull}; !reflect.DeepEqual(got, tt.notNull) {
+				t.Errorf("not null = %v, want %v", got, tt.notNull)
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package transform
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+)
+
+// the lint rules of the naming and design conventions
+const (
+	RuleSnakeCase    = "snake-case"    // the names are in snake_case
+	RulePKRequired   = "pk-required"   // every table has a primary key
+	RulePKName       = "pk-name"       // the single column primary key is named <table>_id
+	RuleFKType       = "fk-type"       // the foreign key column has the type of the referenced column
+	RuleFKTarget     = "fk-target"     // the foreign key references the primary key or a unique key
+	RuleNameLength   = "name-length"   // the names are not longer than the limit of the dialects
+	RuleReservedWord = "reserved-word" // the names are not the reserved words of the dialects
+	RuleDescription  = "description"   // the tables and columns have the description
+)
+
+// SeverityOff disables the lint rule.
+const SeverityOff = "off"
+
+// lintRules are the lint rules and the default severities.
+var lintRules = map[string]string{
+	RuleSnakeCase:    SeverityWarning,
+	RulePKRequired:   SeverityWarning,
+	RulePKName:       SeverityOff,
+	RuleFKType:       SeverityError,
+	RuleFKTarget:     SeverityWarning,
+	RuleNameLength:   SeverityError,
+	RuleReservedWord: SeverityWarning,
+	RuleDescription:  SeverityOff,
+}
+
+// maxNameLengths are the max lengths of the identifiers of the dialects,
+// SQLite has no limit.
+var maxNameLengths = map[string]int{
+	DialectMariaDB:  64,
+	DialectMSSQL:    128,
+	DialectPostgres: 63,
+}
+
+// reservedWords are the reserved words of the dialects.
+var reservedWords = map[string][]string{
+	DialectMariaDB:  mariadbReservedWords,
+	DialectMSSQL:    mssqlReservedWords,
+	DialectPostgres: pgReservedWords,
+	DialectSqlite:   sqliteReservedWords,
+}
+
+var (
+	snakeCaseRegexp = regexp.MustCompile(`^[a-z][a-z0-9]*(_[a-z0-9]+)*$`)
+	// suppressRegexp matches the suppression comment, e.g. # dst:ignore snake-case, description
+	suppressRegexp = regexp.MustCompile(`#\s*dst:ignore\b([^#]*)`)
+)
+
+// LintConfig is the configuration of the lint rules.
+type LintConfig struct {
+	// Dialects are the target dialects of the name length and reserved words
+	// rules, all dialects if empty
+	Dialects []string `yaml:"dialects,flow,omitempty"`
+	// Rules overrides the severities (error, warning or off) of the rules,
+	// e.g. description: warning
+	Rules map[string]string `yaml:"rules,omitempty"`
+}
+
+// LintRules returns the names of the lint rules.
+func LintRules() []string {
+	names := lo.Keys(lintRules)
+	sort.Strings(names)
+	return names
+}
+
+// validate returns an error if any rule, severity or dialect is unknown.
+func (cfg LintConfig) validate() error {
+	for rule, severity := range cfg.Rules {
+		if _, found := lintRules[rule]; !found {
+			return tracerr.Errorf("unknown lint rule '%s', supported rules: %s", rule, strings.Join(LintRules(), ", "))
+		}
+		if !lo.Contains([]string{SeverityError, SeverityWarning, SeverityOff}, strings.ToLower(severity)) {
+			return tracerr.Errorf("invalid severity '%s' of the lint rule '%s', expect error, warning or off", severity, rule)
+		}
+	}
+	for _, dialect := range cfg.Dialects {
+		if _, found := reservedWords[strings.ToLower(dialect)]; !found {
+			return tracerr.Errorf("unknown lint dialect '%s', supported dialects: %s", dialect, strings.Join(LintConfig{}.dialects(), ", "))
+		}
+	}
+	return nil
+}
+
+// severity returns the severity of the rule, off if disabled.
+func (cfg LintConfig) severity(rule string) string {
+	if severity, found := cfg.Rules[rule]; found {
+		return strings.ToLower(severity)
+	}
+	return lintRules[rule]
+}
+
+// dialects returns the target dialects, all dialects if not configured.
+func (cfg LintConfig) dialects() []string {
+	if len(cfg.Dialects) == 0 {
+		dialects := lo.Keys(reservedWords)
+		sort.Strings(dialects)
+		return dialects
+	}
+	return lo.Map(cfg.Dialects, func(d string, _ int) string { return strings.ToLower(d) })
+}
+
+// lint checks the resolved definition by the rules of the configuration.
+func lint(data *DataDef, cfg LintConfig) []Finding {
+	result := make([]Finding, 0)
+	report := func(rule string, f Finding, format string, args ...any) {
+		if severity := cfg.severity(rule); severity != SeverityOff {
+			f.Rule, f.Severity, f.Message = rule, severity, fmt.Sprintf(format, args...)
+			result = append(result, f)
+		}
+	}
+
+	// the shortest max length and the reserved words of the target dialects
+	maxLength, maxDialect := 0, ""
+	reserved := make(map[string][]string)
+	for _, dialect := range cfg.dialects() {
+		if n, found := maxNameLengths[dialect]; found && (maxLength == 0 || n < maxLength) {
+			maxLength, maxDialect = n, dialect
+		}
+		for _, word := range reservedWords[dialect] {
+			reserved[word] = append(reserved[word], dialect)
+		}
+	}
+
+	// lintName checks the name of the kind, e.g. table, column, index
+	lintName := func(f Finding, kind string, name string, named bool) {
+		if named && !snakeCaseRegexp.MatchString(name) {
+			report(RuleSnakeCase, f, "%s name '%s' is not in snake_case", kind, name)
+		}
+		if maxLength > 0 && len(name) > maxLength {
+			report(RuleNameLength, f, "%s name '%s' is longer than %d characters of %s", kind, name, maxLength, maxDialect)
+		}
+		if dialects, found := reserved[strings.ToLower(name)]; found && named {
+			report(RuleReservedWord, f, "%s name '%s' is a reserved word of %s", kind, name, strings.Join(dialects, ", "))
+		}
+	}
+
+	lintColumns := func(schema string, table string, columns []Column) {
+		for _, column := range columns {
+			f := Finding{Schema: schema, Table: table, Column: column.Name, Pos: column.Pos}
+			lintName(f, "column", column.Name, true)
+			if strings.TrimSpace(column.Desc) == "" {
+				report(RuleDescription, f, "missing description of the column '%s'", column.Name)
+			}
+		}
+	}
+
+	// the types are normalized by the postgres mapping which unifies the type aliases, e.g. INT and INTEGER
+	normalizeType := func(dataType string) string {
+		return strings.ToUpper(strings.Join(strings.Fields(MapType(parseColumnType(dataType), DialectPostgres, nil)), ""))
+	}
+	// isKey returns true if the columns are the primary key or a unique key of the table
+	isKey := func(table Table, columns []string) bool {
+		same := func(names []string) bool {
+			return len(names) == len(columns) && len(lo.Intersect(names, columns)) == len(columns)
+		}
+		if same(table.PrimaryKeyColumns(data.Fixed)) {
+			return true
+		}
+		if len(columns) == 1 && lo.ContainsBy(tableColumns(data, table), func(c Column) bool { return c.Name == columns[0] && isYes(c.Unique) }) {
+			return true
+		}
+		return lo.ContainsBy(table.Indexes, func(ix Index) bool { return ix.Unique && ix.Where == "" && same(ix.ColumnNames()) })
+	}
+
+	lintForeignKeys := func(schema string, table Table) {
+		columns := tableColumns(data, table)
+		for i, fk := range data.ForeignKeys(table) {
+			f := Finding{Schema: schema, Table: table.Name, Column: strings.Join(fk.Columns, ", "), Pos: fk.Pos}
+			// the default name is checked by the length only
+			lintName(f, "foreign key", fkName(table.Name, fk), fk.Name != "")
+			label := lo.Ternary(fk.Name != "", fk.Name, fmt.Sprintf("#%d", i+1))
+			rtable, found := findTable(data, fk.RefTable)
+			if !found || len(fk.RefColumns) != len(fk.Columns) {
+				// reported by Verify
+				continue
+			}
+			if !isKey(rtable, fk.RefColumns) {
+				report(RuleFKTarget, f, "[FK: %s] referenced column(s) %s of the table '%s' are not the primary key or a unique key",
+					label, strings.Join(fk.RefColumns, ", "), fk.RefTable)
+			}
+			rcolumns := tableColumns(data, rtable)
+			for j := range fk.Columns {
+				column, found := lo.Find(columns, func(c Column) bool { return c.Name == fk.Columns[j] })
+				rcolumn, rfound := lo.Find(rcolumns, func(c Column) bool { return c.Name == fk.RefColumns[j] })
+				if found && rfound && normalizeType(column.DataType) != normalizeType(rcolumn.DataType) {
+					report(RuleFKType, f, "[FK: %s] type '%s' of the column '%s' does not match the type '%s' of '%s.%s'",
+						label, column.DataType, column.Name, rcolumn.DataType, fk.RefTable, rcolumn.Name)
+				}
+			}
+		}
+	}
+
+	lintColumns("", "fixed", data.Fixed)
+	for _, schema := range data.Schemas {
+		for _, table := range schema.Tables {
+			f := Finding{Schema: schema.Name, Table: table.Name, Pos: table.Pos}
+			lintName(f, "table", table.Name, true)
+			if strings.TrimSpace(table.Desc) == "" {
+				report(RuleDescription, f, "missing description of the table '%s'", table.Name)
+			}
+			pk := table.PrimaryKeyColumns(data.Fixed)
+			if len(pk) == 0 {
+				report(RulePKRequired, f, "missing primary key of the table '%s'", table.Name)
+			}
+			if len(pk) == 1 && pk[0] != table.Name+"_id" {
+				report(RulePKName, f, "primary key '%s' of the table '%s' is not named '%s_id'", pk[0], table.Name, table.Name)
+			}
+			lintColumns(schema.Name, table.Name, table.Columns)
+			lintForeignKeys(schema.Name, table)
+			for _, ix := range table.Indexes {
+				f := Finding{Schema: schema.Name, Table: table.Name, Column: strings.Join(ix.ColumnNames(), ", "), Pos: ix.Pos}
+				lintName(f, "index", indexName(table.Name, ix), ix.Name != "")
+			}
+			for _, check := range table.Checks {
+				if check.Name != "" {
+					lintName(Finding{Schema: schema.Name, Table: table.Name, Pos: check.Pos}, "check", check.Name, true)
+				}
+			}
+		}
+	}
+	return result
+}
+
+// suppress returns the findings not suppressed by the comment in the yaml
+// file, which is at the end of the line of the element or the line above it,
+// e.g. # dst:ignore snake-case, description. All lint rules are suppressed if
+// no rule is given, the findings of the other rules are never suppressed.
+func suppress(findings []Finding) []Finding {
+	files := make(map[string][]string)
+	lines := func(file string) []string {
+		if content, found := files[file]; found {
+			return content
+		}
+		content := make([]string, 0)
+		if ext := strings.ToLower(filepath.Ext(file)); ext == ".yml" || ext == ".yaml" {
+			if fh, err := os.Open(file); err == nil {
+				scanner := bufio.NewScanner(fh)
+				for scanner.Scan() {
+					content = append(content, scanner.Text())
+				}
+				fh.Close()
+			}
+		}
+		files[file] = content
+		return content
+	}
+	// ignored returns true if the line has the suppression comment of the rule
+	ignored := func(line string, rule string) bool {
+		m := suppressRegexp.FindStringSubmatch(line)
+		if m == nil {
+			return false
+		}
+		rules := lo.Compact(lo.Map(strings.FieldsFunc(m[1], func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }),
+			func(s string, _ int) string { return strings.TrimSpace(s) }))
+		return len(rules) == 0 || lo.Contains(rules, rule)
+	}
+
+	return lo.Filter(findings, func(f Finding, _ int) bool {
+		if _, found := lintRules[f.Rule]; !found || f.Pos.File == "" || f.Pos.Line == 0 {
+			return true
+		}
+		content := lines(f.Pos.File)
+		if f.Pos.Line > len(content) {
+			return true
+		}
+		if ignored(content[f.Pos.Line-1], f.Rule) {
+			return false
+		}
+		// the comment line above the element
+		if f.Pos.Line > 1 {
+			if above := strings.TrimSpace(content[f.Pos.Line-2]); strings.HasPrefix(above, "#") && ignored(above, f.Rule) {
+				return false
+			}
+		}
+		return true
+	})
+}
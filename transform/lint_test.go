@@ -0,0 +1,89 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+// findingRules returns the rule and severity of the findings.
+func findingRules(findings []Finding) string {
+	return strings.Join(lo.Map(findings, func(f Finding, _ int) string { return f.Rule + " " + f.Severity }), "\n")
+}
+
+func TestVerifySuppress(t *testing.T) {
+	data := readTestYml(t, `schemas:
+  - name: app
+    tables:
+      # dst:ignore
+      - name: Doc
+        columns:
+          - { na: doc_id, ty: INT, id: Y, nu: Y }
+          - { na: kind } # dst:ignore
+          - { na: tag_id, ty: INT, fk: tag.tag_id } # dst:ignore foreign-key, column-type
+          - { na: user, ty: VARCHAR(10) } # dst:ignore reserved-word
+          - { na: Title, ty: VARCHAR(10) } # dst:ignore reserved-word
+`)
+	got := findingRules(Verify(data, LintConfig{}))
+	want := strings.Join([]string{
+		"column-type error",
+		"foreign-key error",
+		"snake-case warning",
+	}, "\n")
+	if got != want {
+		t.Errorf("findings =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestVerifyLintConfig(t *testing.T) {
+	data := readTestYml(t, `schemas:
+  - name: app
+    tables:
+      - name: doc
+        columns:
+          - { na: doc_id, ty: INT, id: Y, nu: Y }
+          - { na: user, ty: VARCHAR(10) }
+`)
+	tests := []struct {
+		name string
+		cfg  LintConfig
+		want string
+	}{
+		{"default", LintConfig{}, "reserved-word warning"},
+		{"dialects", LintConfig{Dialects: []string{DialectSqlite}}, ""},
+		{"rules", LintConfig{Rules: map[string]string{RuleReservedWord: "Error", RuleDescription: SeverityWarning}},
+			"description warning\ndescription warning\nreserved-word error\ndescription warning"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := findingRules(Verify(data, tt.cfg)); got != tt.want {
+				t.Errorf("findings =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyStructure(t *testing.T) {
+	// the lint errors (fk-type, name-length) do not stop the conversion
+	data := readTestYml(t, `schemas:
+  - name: app
+    tables:
+      - name: Doc
+        columns:
+          - { na: doc_id, ty: INT, id: Y, nu: Y }
+          - { na: `+strings.Repeat("x", 70)+`, ty: INT }
+          - { na: tag_id, ty: VARCHAR(10), fk: tag.tag_id }
+      - name: tag
+        columns:
+          - { na: tag_id, ty: INT, id: Y, nu: Y }
+`)
+	if got := findingRules(VerifyStructure(data)); got != "" {
+		t.Errorf("findings =\n%s\nwant none", got)
+	}
+	got := findingRules(Verify(data, LintConfig{}))
+	want := strings.Join([]string{"snake-case warning", "name-length error", "fk-type error"}, "\n")
+	if got != want {
+		t.Errorf("lint findings =\n%s\nwant\n%s", got, want)
+	}
+}
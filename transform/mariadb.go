@@ -0,0 +1,357 @@
+package transform
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	_ "github.com/go-sql-driver/mysql"
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+)
+
+// mariadbReservedWords are the reserved words of MariaDB, which must be quoted
+// when used as identifier.
+var mariadbReservedWords = []string{
+	"add", "all", "alter", "analyze", "and", "as", "asc", "before", "between", "bigint", "binary", "blob",
+	"both", "by", "call", "cascade", "case", "change", "char", "character", "check", "collate", "column",
+	"condition", "constraint", "continue", "convert", "create", "cross", "current_date", "current_time",
+	"current_timestamp", "current_user", "cursor", "database", "databases", "decimal", "declare", "default",
+	"delete", "desc", "describe", "distinct", "div", "double", "drop", "dual", "each", "else", "elseif",
+	"enclosed", "escaped", "exists", "exit", "explain", "false", "fetch", "float", "for", "force", "foreign",
+	"from", "fulltext", "grant", "group", "having", "if", "ignore", "in", "index", "inner", "insert", "int",
+	"integer", "interval", "into", "is", "iterate", "join", "key", "keys", "kill", "leading", "leave", "left",
+	"like", "limit", "lines", "load", "localtime", "localtimestamp", "lock", "long", "loop", "match", "mod",
+	"natural", "not", "null", "numeric", "on", "optimize", "option", "or", "order", "out", "outer", "precision",
+	"primary", "procedure", "purge", "range", "read", "real", "references", "regexp", "release", "rename",
+	"repeat", "replace", "require", "restrict", "return", "revoke", "right", "rlike", "schema", "schemas",
+	"select", "separator", "set", "show", "smallint", "spatial", "sql", "ssl", "starting", "table",
+	"terminated", "then", "tinyint", "to", "trailing", "trigger", "true", "undo", "union", "unique", "unlock",
+	"unsigned", "update", "usage", "use", "using", "values", "varchar", "varying", "when", "where", "while",
+	"with", "write", "xor", "zerofill",
+}
+
+// mariadbColumn returns the column definition of CREATE TABLE, ADD COLUMN and
+// MODIFY COLUMN, the identifiers are not quoted as the mariadb template.
+func mariadbColumn(column Column, types TypeMappings) string {
+	line := fmt.Sprintf("%s %s", column.Name, MapType(column.Type(), DialectMariaDB, types))
+	if isYes(column.NotNull) {
+		line += " NOT NULL"
+	}
+	if column.Value != "" {
+		line += " DEFAULT " + sqlDefault(column.Value)
+	}
+	if isYes(column.Identity) {
+		line += " AUTO_INCREMENT"
+	}
+	if column.Desc != "" {
+		line += " COMMENT " + quoteString(column.Desc)
+	}
+	return line
+}
+
+// mariadbMigrator writes the MariaDB statements of the changes, types are the
+// type mappings.
+type mariadbMigrator struct {
+	types TypeMappings
+}
+
+func (m mariadbMigrator) createTable(c Change) string {
+	table := c.New.Table
+	lines := lo.Map(table.Columns, func(column Column, _ int) string { return "      " + mariadbColumn(column, m.types) })
+	if len(table.PrimaryKey) > 0 {
+		lines = append(lines, fmt.Sprintf("      PRIMARY KEY (%s)", strings.Join(table.PrimaryKey, ", ")))
+	}
+	for _, column := range table.Columns {
+		if isYes(column.Unique) {
+			lines = append(lines, fmt.Sprintf("      CONSTRAINT %s UNIQUE (%s)", uniqueName(table.Name, column.Name), column.Name))
+		}
+	}
+	for _, check := range tableChecks(&DataDef{}, table, func(name string) string { return name }) {
+		lines = append(lines, fmt.Sprintf("      CONSTRAINT %s CHECK (%s)", check.Name, check.Expr))
+	}
+	return fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n%s\n);\n", table.Name, strings.Join(lines, ",\n"))
+}
+
+func (m mariadbMigrator) dropTable(c Change) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", c.Table)
+}
+
+func (m mariadbMigrator) addColumn(c Change) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s;\n", c.Table, mariadbColumn(c.New.Column, m.types))
+}
+
+func (m mariadbMigrator) alterColumn(c Change) string {
+	return fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s;\n", c.Table, mariadbColumn(c.New.Column, m.types))
+}
+
+func (m mariadbMigrator) dropColumn(c Change) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN IF EXISTS %s;\n", c.Table, c.Name)
+}
+
+func (m mariadbMigrator) createIndex(c Change) string {
+	ix := c.New.Index
+	if ix.Where != "" {
+		return fmt.Sprintf("-- %s skipped, partial index is not supported: WHERE %s\n", c.Name, ix.Where)
+	}
+	sql := fmt.Sprintf("CREATE %sINDEX IF NOT EXISTS %s ON %s (%s);\n",
+		lo.Ternary(ix.Unique, "UNIQUE ", ""), c.Name, c.Table, strings.Join(ix.Columns, ", "))
+	if len(ix.Include) > 0 {
+		sql += fmt.Sprintf("-- %s included columns skipped, INCLUDE is not supported: %s\n", c.Name, strings.Join(ix.Include, ", "))
+	}
+	return sql
+}
+
+func (m mariadbMigrator) dropIndex(c Change) string {
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s ON %s;\n", c.Name, c.Table)
+}
+
+func (m mariadbMigrator) addForeignKey(c Change) string {
+	fk := c.New.ForeignKey
+	return fmt.Sprintf("ALTER TABLE IF EXISTS %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)%s;\n",
+		c.Table, c.Name, strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "), fkActionClause(fk))
+}
+
+func (m mariadbMigrator) dropForeignKey(c Change) string {
+	return fmt.Sprintf("ALTER TABLE IF EXISTS %s DROP CONSTRAINT IF EXISTS %s;\n", c.Table, c.Name)
+}
+
+func (m mariadbMigrator) addCheck(c Change) string {
+	return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s);\n", c.Table, c.Name, c.New.Check.Expr)
+}
+
+func (m mariadbMigrator) dropCheck(c Change) string {
+	return fmt.Sprintf("ALTER TABLE IF EXISTS %s DROP CONSTRAINT IF EXISTS %s;\n", c.Table, c.Name)
+}
+
+// the unique constraint is the unique index of MariaDB
+func (m mariadbMigrator) addUnique(c Change) string {
+	return fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (%s);\n", c.Name, c.Table, c.New.Column.Name)
+}
+
+func (m mariadbMigrator) dropUnique(c Change) string {
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s ON %s;\n", c.Name, c.Table)
+}
+
+// mariadbSystemSchemas are the schemas not imported
+var mariadbSystemSchemas = []string{"information_schema", "mysql", "performance_schema", "sys"}
+
+// mariadbWidthRegexp matches the display width of the integer types, e.g. int(11)
+var mariadbWidthRegexp = regexp.MustCompile(`^(?i)(SMALLINT|MEDIUMINT|INT|BIGINT)\(\d+\)`)
+
+// mariadbImportType returns the data type of COLUMN_TYPE, the display width
+// of the integer types is removed except TINYINT(1) (boolean).
+func mariadbImportType(columnType string) string {
+	return mariadbWidthRegexp.ReplaceAllString(strings.ToUpper(columnType), "$1")
+}
+
+// mariadbImportAction returns the rule of the foreign key, RESTRICT (the
+// default of MariaDB, same as NO ACTION) is empty.
+func mariadbImportAction(rule string) string {
+	return lo.Ternary(strings.EqualFold(rule, "RESTRICT"), "", importAction(rule))
+}
+
+// ImportMariaDB reads the definition of the MariaDB (or MySQL) database of the
+// data source name, e.g. user:pass@tcp(localhost:3306)/db, which are the
+// tables of the schemas matching the patterns (the database of the data
+// source name if no schema pattern) with the columns, comments, primary keys,
+// foreign keys, unique constraints, checks and indexes. The objects cannot be
+// imported are reported as the warnings, e.g. the fulltext indexes.
+func ImportMariaDB(dsn string, schemaPattern string, tablePattern string) (*DataDef, []Finding, error) {
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		return nil, nil, tracerr.Wrap(err)
+	}
+	defer db.Close()
+	var current sql.NullString
+	if err := db.QueryRow("SELECT DATABASE()").Scan(&current); err != nil {
+		return nil, nil, tracerr.Wrap(err)
+	}
+	matchSchema := func(name string) bool {
+		if schemaPattern == "" && current.Valid {
+			return name == current.String
+		}
+		return matchPattern(schemaPattern, name) && !lo.Contains(mariadbSystemSchemas, strings.ToLower(name))
+	}
+
+	data := &DataDef{Schemas: make([]Schema, 0)}
+	err = queryRows(db, `SELECT TABLE_SCHEMA, TABLE_NAME, TABLE_COMMENT FROM information_schema.TABLES
+		WHERE TABLE_TYPE = 'BASE TABLE' ORDER BY TABLE_SCHEMA, TABLE_NAME`, nil, func(rows *sql.Rows) error {
+		var schema string
+		var table Table
+		if err := rows.Scan(&schema, &table.Name, &table.Desc); err != nil {
+			return err
+		}
+		if !matchSchema(schema) || !matchPattern(tablePattern, table.Name) {
+			return nil
+		}
+		if len(data.Schemas) == 0 || data.Schemas[len(data.Schemas)-1].Name != schema {
+			data.Schemas = append(data.Schemas, Schema{Name: schema})
+		}
+		s := &data.Schemas[len(data.Schemas)-1]
+		s.Tables = append(s.Tables, table)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	findings := make([]Finding, 0)
+	for i := range data.Schemas {
+		schema := &data.Schemas[i]
+		for j := range schema.Tables {
+			table := &schema.Tables[j]
+			warnings, err := mariadbImportTable(db, schema.Name, table)
+			if err != nil {
+				return nil, nil, err
+			}
+			for _, warning := range warnings {
+				findings = append(findings, importWarning(schema.Name, table.Name, warning))
+			}
+		}
+	}
+	return data, findings, nil
+}
+
+// mariadbImportTable reads the columns, constraints and indexes of the table,
+// it returns the warnings of the objects not imported.
+func mariadbImportTable(db *sql.DB, schema string, table *Table) ([]string, error) {
+	warnings := make([]string, 0)
+	args := []interface{}{schema, table.Name}
+
+	// columns
+	err := queryRows(db, `SELECT COLUMN_NAME, COLUMN_TYPE, IS_NULLABLE = 'NO', COLUMN_DEFAULT, EXTRA, COLUMN_COMMENT
+		FROM information_schema.COLUMNS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY ORDINAL_POSITION`, args, func(rows *sql.Rows) error {
+		var column Column
+		var columnType, extra string
+		var notNull bool
+		var value sql.NullString
+		if err := rows.Scan(&column.Name, &columnType, &notNull, &value, &extra, &column.Desc); err != nil {
+			return err
+		}
+		column.DataType = mariadbImportType(columnType)
+		column.NotNull = lo.Ternary(notNull, "Y", "")
+		extra = strings.ToLower(extra)
+		switch {
+		case strings.Contains(extra, "auto_increment"):
+			column.Identity = "Y"
+		case strings.Contains(extra, "generated"):
+			warnings = append(warnings, fmt.Sprintf("generated column '%s' is imported as a plain column", column.Name))
+		default:
+			column.Value = importDefault(value.String)
+		}
+		if strings.Contains(extra, "on update") {
+			warnings = append(warnings, fmt.Sprintf("%s of the column '%s' is not imported", extra[strings.Index(extra, "on update"):], column.Name))
+		}
+		table.Columns = append(table.Columns, column)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// primary key, unique constraints and foreign keys, the columns are in the
+	// order of the key
+	type constraint struct {
+		kind string
+		fk   ForeignKey
+	}
+	constraints := make([]constraint, 0)
+	err = queryRows(db, `SELECT tc.CONSTRAINT_NAME, tc.CONSTRAINT_TYPE, k.COLUMN_NAME,
+			COALESCE(k.REFERENCED_TABLE_NAME, ''), COALESCE(k.REFERENCED_COLUMN_NAME, ''), COALESCE(r.DELETE_RULE, ''), COALESCE(r.UPDATE_RULE, '')
+		FROM information_schema.TABLE_CONSTRAINTS tc
+		JOIN information_schema.KEY_COLUMN_USAGE k ON k.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+			AND k.TABLE_NAME = tc.TABLE_NAME AND k.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+		LEFT JOIN information_schema.REFERENTIAL_CONSTRAINTS r ON r.CONSTRAINT_SCHEMA = tc.CONSTRAINT_SCHEMA
+			AND r.TABLE_NAME = tc.TABLE_NAME AND r.CONSTRAINT_NAME = tc.CONSTRAINT_NAME
+		WHERE tc.TABLE_SCHEMA = ? AND tc.TABLE_NAME = ? AND tc.CONSTRAINT_TYPE IN ('PRIMARY KEY', 'UNIQUE', 'FOREIGN KEY')
+		ORDER BY tc.CONSTRAINT_NAME, k.ORDINAL_POSITION`, args, func(rows *sql.Rows) error {
+		var name, kind, column, refTable, refColumn, onDelete, onUpdate string
+		if err := rows.Scan(&name, &kind, &column, &refTable, &refColumn, &onDelete, &onUpdate); err != nil {
+			return err
+		}
+		if len(constraints) == 0 || constraints[len(constraints)-1].fk.Name != name {
+			constraints = append(constraints, constraint{kind: kind, fk: ForeignKey{Name: name, RefTable: refTable,
+				OnDelete: mariadbImportAction(onDelete), OnUpdate: mariadbImportAction(onUpdate)}})
+		}
+		fk := &constraints[len(constraints)-1].fk
+		fk.Columns = append(fk.Columns, column)
+		if refColumn != "" {
+			fk.RefColumns = append(fk.RefColumns, refColumn)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range constraints {
+		switch c.kind {
+		case "PRIMARY KEY":
+			importPrimaryKey(table, c.fk.Columns)
+		case "UNIQUE":
+			importUnique(table, c.fk.Name, c.fk.Columns)
+		case "FOREIGN KEY":
+			importForeignKey(table, c.fk)
+		}
+	}
+
+	// checks, TABLE_NAME is not available in MySQL
+	err = queryRows(db, `SELECT CONSTRAINT_NAME, CHECK_CLAUSE FROM information_schema.CHECK_CONSTRAINTS
+		WHERE CONSTRAINT_SCHEMA = ? AND TABLE_NAME = ? ORDER BY CONSTRAINT_NAME`, args, func(rows *sql.Rows) error {
+		var check Check
+		if err := rows.Scan(&check.Name, &check.Expr); err != nil {
+			return err
+		}
+		importCheck(table, check)
+		return nil
+	})
+	if err != nil {
+		warnings = append(warnings, "checks are not imported: "+err.Error())
+	}
+
+	// indexes, except the indexes of the constraints (the index of a foreign
+	// key is created by MariaDB if not exists)
+	indexes := make([]Index, 0)
+	skipped := make(map[string]string)
+	err = queryRows(db, `SELECT INDEX_NAME, NON_UNIQUE = 0, COLUMN_NAME, COALESCE(COLLATION, 'A') = 'D', SUB_PART IS NOT NULL, INDEX_TYPE
+		FROM information_schema.STATISTICS WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?
+		ORDER BY INDEX_NAME, SEQ_IN_INDEX`, args, func(rows *sql.Rows) error {
+		var name, indexType string
+		var column sql.NullString
+		var unique, desc, prefix bool
+		if err := rows.Scan(&name, &unique, &column, &desc, &prefix, &indexType); err != nil {
+			return err
+		}
+		switch {
+		case indexType == "FULLTEXT" || indexType == "SPATIAL":
+			skipped[name] = fmt.Sprintf("%s index '%s' is not imported", strings.ToLower(indexType), name)
+		case !column.Valid:
+			skipped[name] = fmt.Sprintf("index '%s' on the expression is not imported", name)
+		case prefix:
+			skipped[name] = fmt.Sprintf("index '%s' on the column prefix is not imported", name)
+		}
+		if len(indexes) == 0 || indexes[len(indexes)-1].Name != name {
+			indexes = append(indexes, Index{Name: name, Unique: unique})
+		}
+		ix := &indexes[len(indexes)-1]
+		ix.Columns = append(ix.Columns, column.String+lo.Ternary(desc, " DESC", ""))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, ix := range indexes {
+		c, found := lo.Find(constraints, func(c constraint) bool { return c.fk.Name == ix.Name })
+		if ix.Name == "PRIMARY" || found && (c.kind != "FOREIGN KEY" || strings.Join(c.fk.Columns, ",") == strings.Join(ix.Columns, ",")) {
+			continue
+		}
+		if warning, found := skipped[ix.Name]; found {
+			warnings = append(warnings, warning)
+			continue
+		}
+		importIndex(table, ix)
+	}
+	return warnings, nil
+}
@@ -0,0 +1,114 @@
+package transform
+
+import (
+	"database/sql"
+	"os"
+	"testing"
+)
+
+func TestMariadbImportType(t *testing.T) {
+	tests := []struct{ columnType, want string }{
+		{"int(11)", "INT"},
+		{"bigint(20) unsigned", "BIGINT UNSIGNED"},
+		{"smallint(6)", "SMALLINT"},
+		// the width of tinyint(1) is the boolean
+		{"tinyint(1)", "TINYINT(1)"},
+		{"varchar(50)", "VARCHAR(50)"},
+		{"decimal(10,2)", "DECIMAL(10,2)"},
+		{"datetime", "DATETIME"},
+	}
+	for _, tt := range tests {
+		if got := mariadbImportType(tt.columnType); got != tt.want {
+			t.Errorf("mariadbImportType(%q) = %q, want %q", tt.columnType, got, tt.want)
+		}
+	}
+}
+
+func TestMariadbImportAction(t *testing.T) {
+	tests := []struct{ rule, want string }{
+		{"RESTRICT", ""},
+		{"NO ACTION", ""},
+		{"CASCADE", "CASCADE"},
+		{"SET NULL", "SET NULL"},
+	}
+	for _, tt := range tests {
+		if got := mariadbImportAction(tt.rule); got != tt.want {
+			t.Errorf("mariadbImportAction(%q) = %q, want %q", tt.rule, got, tt.want)
+		}
+	}
+}
+
+// TestImportMariaDB imports the tables from the database of
+// DST_TEST_MARIADB_DSN, e.g. root:dst@tcp(localhost:53306)/dst of the
+// container of make import-mariadb. The database dst_test is dropped.
+func TestImportMariaDB(t *testing.T) {
+	dsn := os.Getenv("DST_TEST_MARIADB_DSN")
+	if dsn == "" {
+		t.Skip("DST_TEST_MARIADB_DSN is not set")
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	drop := func() {
+		if _, err := db.Exec("DROP DATABASE IF EXISTS dst_test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	drop()
+	defer drop()
+	for _, stmt := range []string{
+		"CREATE DATABASE dst_test",
+		`CREATE TABLE dst_test.tag (
+			tag_id INT NOT NULL AUTO_INCREMENT,
+			name VARCHAR(50) NOT NULL,
+			PRIMARY KEY (tag_id),
+			UNIQUE KEY uq_tag_name (name)
+		)`,
+		`CREATE TABLE dst_test.doc (
+			doc_id BIGINT NOT NULL,
+			ver INT NOT NULL DEFAULT 0,
+			title VARCHAR(100) DEFAULT 'none',
+			tag_id INT,
+			created DATETIME,
+			PRIMARY KEY (doc_id, ver),
+			CONSTRAINT fk_doc_tag_id FOREIGN KEY (tag_id) REFERENCES dst_test.tag (tag_id) ON DELETE CASCADE,
+			INDEX ix_doc_created (created, title)
+		)`,
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	data, findings, err := ImportMariaDB(dsn, "dst_test", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) > 0 {
+		t.Errorf("unexpected findings %v", findings)
+	}
+	// the integer display width is removed and the index of the foreign key
+	// is not imported
+	assertDataDef(t, data, `schemas:
+  - name: dst_test
+    tables:
+      - name: doc
+        primary_key: [doc_id, ver]
+        columns:
+          - {na: doc_id, ty: BIGINT, nu: Y}
+          - {na: ver, ty: INT, nu: Y, va: "0"}
+          - {na: title, ty: VARCHAR(100), va: none}
+          - {na: tag_id, ty: INT}
+          - {na: created, ty: DATETIME}
+        foreign_keys:
+          - {columns: [tag_id], ref_table: tag, ref_columns: [tag_id], on_delete: CASCADE}
+        indexes:
+          - {name: ix_doc_created, columns: [created, title]}
+      - name: tag
+        columns:
+          - {na: tag_id, ty: INT, id: Y, nu: Y}
+          - {na: name, ty: VARCHAR(50), nu: Y, un: Y}
+`)
+}
@@ -0,0 +1,190 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+func init() {
+	Register(Format{
+		Name:    "markdown",
+		Aliases: []string{"m", "md"},
+		Usage:   "data dictionary in markdown",
+		Exts:    []string{".md"},
+		Writer:  WriterFunc(func(data *DataDef, out string, _ Options) error { return WriteMd(data, out) }),
+	})
+}
+
+// WriteMd writes the data dictionary in markdown, one section for each table
+// with the fixed columns appended.
+func WriteMd(data *DataDef, out string) error {
+	var sb strings.Builder
+
+	cell := func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", "<br>")
+	}
+	writeColumn := func(column Column, fixed bool, pk []string) {
+		keys := make([]string, 0)
+		if lo.Contains(pk, column.Name) {
+			keys = append(keys, "PK")
+		}
+		if column.ForeignKey != "" {
+			keys = append(keys, "FK")
+		}
+		key := strings.Join(keys, ", ")
+		name := column.Name
+		if fixed {
+			name = "_" + name + "_"
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			key, cell(name), cell(column.Title), cell(column.DataType), column.NotNull, column.Unique,
+			cell(column.Value), cell(column.ForeignKey), cell(column.Desc)))
+	}
+
+	for _, schema := range data.Schemas {
+		sb.WriteString(fmt.Sprintf("# %s\n\n", schema.Name))
+		if schema.Desc != "" {
+			sb.WriteString(schema.Desc + "\n\n")
+		}
+		for _, table := range schema.Tables {
+			sb.WriteString(fmt.Sprintf("## %s", table.Name))
+			if table.Title != "" {
+				sb.WriteString(fmt.Sprintf(" (%s)", table.Title))
+			}
+			sb.WriteString("\n\n")
+			if table.Desc != "" {
+				sb.WriteString(table.Desc + "\n\n")
+			}
+			sb.WriteString("| Key | Column Name | Title | Data Type | Not Null | Unique | Default | Foreign Key | Description |\n")
+			sb.WriteString("|---|---|---|---|---|---|---|---|---|\n")
+			pk := table.PrimaryKeyColumns(data.Fixed)
+			for _, column := range table.Columns {
+				writeColumn(column, false, pk)
+			}
+			for _, column := range table.FixedColumns(data.Fixed) {
+				writeColumn(column, true, pk)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return writeText(out, sb.String())
+}
+
+// changeDetail returns the summary of the changed object, the altered object
+// is shown as old → new.
+func changeDetail(c Change) string {
+	column := func(col Column) string {
+		s := col.DataType
+		if isYes(col.NotNull) {
+			s += " NOT NULL"
+		}
+		if col.Value != "" {
+			s += " DEFAULT " + col.Value
+		}
+		return s
+	}
+	foreignKey := func(fk ForeignKey) string {
+		return fmt.Sprintf("(%s) → %s (%s)%s", strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "), fkActionClause(fk))
+	}
+	index := func(ix Index) string {
+		s := lo.Ternary(ix.Unique, "UNIQUE ", "") + "(" + strings.Join(ix.Columns, ", ") + ")"
+		if len(ix.Include) > 0 {
+			s += " INCLUDE (" + strings.Join(ix.Include, ", ") + ")"
+		}
+		if ix.Where != "" {
+			s += " WHERE " + ix.Where
+		}
+		return s
+	}
+	or := func(v string) string { return lo.Ternary(v != "", v, "none") }
+
+	switch c.Object {
+	case ObjectTable:
+		table := lo.Ternary(c.New != nil, c.New, c.Old).Table
+		return fmt.Sprintf("%d column(s)", len(table.Columns))
+	case ObjectColumn:
+		if c.Kind != ChangeAlter {
+			return column(lo.Ternary(c.New != nil, c.New, c.Old).Column)
+		}
+		details := make([]string, 0)
+		for _, field := range c.Fields {
+			o, n := c.Old.Column, c.New.Column
+			switch field {
+			case FieldType:
+				details = append(details, fmt.Sprintf("type: %s → %s", o.DataType, n.DataType))
+			case FieldNotNull:
+				details = append(details, fmt.Sprintf("not null: %s → %s",
+					lo.Ternary(isYes(o.NotNull), "Y", "N"), lo.Ternary(isYes(n.NotNull), "Y", "N")))
+			case FieldDefault:
+				details = append(details, fmt.Sprintf("default: %s → %s", or(o.Value), or(n.Value)))
+			}
+		}
+		return strings.Join(details, "; ")
+	case ObjectForeignKey:
+		if c.Kind == ChangeAlter {
+			return foreignKey(c.Old.ForeignKey) + " ⇒ " + foreignKey(c.New.ForeignKey)
+		}
+		return foreignKey(lo.Ternary(c.New != nil, c.New, c.Old).ForeignKey)
+	case ObjectIndex:
+		if c.Kind == ChangeAlter {
+			return index(c.Old.Index) + " ⇒ " + index(c.New.Index)
+		}
+		return index(lo.Ternary(c.New != nil, c.New, c.Old).Index)
+	case ObjectCheck:
+		if c.Kind == ChangeAlter {
+			return c.Old.Check.Expr + " ⇒ " + c.New.Check.Expr
+		}
+		return lo.Ternary(c.New != nil, c.New, c.Old).Check.Expr
+	case ObjectUnique:
+		return "UNIQUE (" + lo.Ternary(c.New != nil, c.New, c.Old).Column.Name + ")"
+	}
+	return ""
+}
+
+// WriteDiffMd writes the changes in markdown for the code review, the
+// breaking changes (see Change.Breaking) are listed first and marked in the
+// table of all changes with the compatibility classes.
+func WriteDiffMd(changes []Change, title string, out string) error {
+	var sb strings.Builder
+
+	cell := func(s string) string {
+		return strings.ReplaceAll(strings.ReplaceAll(s, "|", "\\|"), "\n", "<br>")
+	}
+	tableName := func(c Change) string {
+		return lo.Ternary(c.Schema != "", c.Schema+"."+c.Table, c.Table)
+	}
+	breaking := lo.Filter(changes, func(c Change, _ int) bool { return c.Breaking() })
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", lo.Ternary(title != "", title, "Schema changes")))
+	if len(changes) == 0 {
+		sb.WriteString("No changes.\n")
+		return writeText(out, sb.String())
+	}
+	sb.WriteString(fmt.Sprintf("%d change(s), %d breaking.\n\n", len(changes), len(breaking)))
+
+	if len(breaking) > 0 {
+		sb.WriteString("## Breaking changes\n\n")
+		for _, c := range breaking {
+			name := lo.Ternary(c.Name != "", tableName(c)+"."+c.Name, tableName(c))
+			sb.WriteString(fmt.Sprintf("- :warning: %s %s `%s` (%s): %s\n", c.Kind, c.Object, name, c.Class, changeDetail(c)))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString("## Changes\n\n")
+	sb.WriteString("| Class | Change | Table | Name | Details |\n")
+	sb.WriteString("|---|---|---|---|---|\n")
+	for _, c := range changes {
+		details := changeDetail(c)
+		if c.Rename != "" {
+			details += fmt.Sprintf(" (renamed %s %s?)", lo.Ternary(c.Kind == ChangeDrop, "to", "from"), c.Rename)
+		}
+		sb.WriteString(fmt.Sprintf("| %s%s | %s %s | %s | %s | %s |\n", lo.Ternary(c.Breaking(), ":warning: ", ""),
+			c.Class, c.Kind, c.Object, cell(tableName(c)), cell(c.Name), cell(details)))
+	}
+
+	return writeText(out, sb.String())
+}
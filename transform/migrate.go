@@ -0,0 +1,105 @@
+package transform
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+)
+
+// migrator returns the SQL statements of the changes in a dialect, the added
+// objects are in Change.New, the dropped objects are in Change.Old, and both
+// for the altered objects.
+type migrator interface {
+	createTable(c Change) string
+	dropTable(c Change) string
+	addColumn(c Change) string
+	alterColumn(c Change) string
+	dropColumn(c Change) string
+	createIndex(c Change) string
+	dropIndex(c Change) string
+	addForeignKey(c Change) string
+	dropForeignKey(c Change) string
+	addCheck(c Change) string
+	dropCheck(c Change) string
+	addUnique(c Change) string
+	dropUnique(c Change) string
+}
+
+// migrators are the migrators of the dialects, data is the target definition,
+// changes are all changes of the script and types are the type mappings.
+var migrators = map[string]func(data *DataDef, changes []Change, types TypeMappings) migrator{
+	DialectMariaDB:  func(_ *DataDef, _ []Change, types TypeMappings) migrator { return mariadbMigrator{types: types} },
+	DialectMSSQL:    func(_ *DataDef, _ []Change, types TypeMappings) migrator { return mssqlMigrator{types: types} },
+	DialectPostgres: newPgMigrator,
+	DialectSqlite:   newSqliteMigrator,
+}
+
+// migrationCheck returns the added check of the change, the column names of
+// the enum values are quoted by qi.
+func migrationCheck(c Change, qi func(string) string) Check {
+	check, found := lo.Find(tableChecks(&DataDef{}, c.New.Table, qi), func(ck Check) bool { return ck.Name == c.Name })
+	return lo.Ternary(found, check, c.New.Check)
+}
+
+// MigrationDialects returns the dialects supported by WriteMigration.
+func MigrationDialects() []string {
+	dialects := lo.Keys(migrators)
+	sort.Strings(dialects)
+	return dialects
+}
+
+// migrationSQL returns the statements of the changes, the statements are in
+// the order that the dependent objects are dropped first and created last:
+// foreign keys, indexes, checks and unique constraints are dropped, tables
+// and columns are created, altered and dropped, and then the unique
+// constraints, checks, indexes and foreign keys are created.
+func migrationSQL(m migrator, changes []Change) string {
+	var sb strings.Builder
+	phase := func(object string, kinds []string, write func(Change) string) {
+		for _, c := range changes {
+			if c.Object == object && lo.Contains(kinds, c.Kind) {
+				sb.WriteString(write(c))
+			}
+		}
+	}
+	dropKinds, addKinds := []string{ChangeDrop, ChangeAlter}, []string{ChangeAdd, ChangeAlter}
+
+	phase(ObjectForeignKey, dropKinds, m.dropForeignKey)
+	phase(ObjectIndex, dropKinds, m.dropIndex)
+	phase(ObjectCheck, dropKinds, m.dropCheck)
+	phase(ObjectUnique, []string{ChangeDrop}, m.dropUnique)
+	phase(ObjectTable, []string{ChangeAdd}, m.createTable)
+	phase(ObjectColumn, []string{ChangeAdd}, m.addColumn)
+	phase(ObjectColumn, []string{ChangeAlter}, m.alterColumn)
+	phase(ObjectColumn, []string{ChangeDrop}, m.dropColumn)
+	phase(ObjectTable, []string{ChangeDrop}, m.dropTable)
+	phase(ObjectUnique, []string{ChangeAdd}, m.addUnique)
+	phase(ObjectCheck, addKinds, m.addCheck)
+	phase(ObjectIndex, addKinds, m.createIndex)
+	phase(ObjectForeignKey, addKinds, m.addForeignKey)
+	return sb.String()
+}
+
+// WriteMigration writes the goose migration script of the dialect from the
+// old definition to the new one, the up script applies the changes (see Diff)
+// and the down script reverts them. The logical types are mapped by the type
+// mappings, nil for the built-in ones.
+func WriteMigration(oldData *DataDef, newData *DataDef, dialect string, types TypeMappings, out string) error {
+	newMigrator, found := migrators[strings.ToLower(dialect)]
+	if !found {
+		return tracerr.Errorf("unsupported dialect '%s', supported dialects: %s", dialect, strings.Join(MigrationDialects(), ", "))
+	}
+
+	var sb strings.Builder
+	up := Diff(oldData, newData)
+	sb.WriteString("-- +goose Up\n")
+	sb.WriteString(migrationSQL(newMigrator(newData, up, types), up))
+
+	down := Diff(newData, oldData)
+	sb.WriteString("\n-- +goose Down\n")
+	sb.WriteString(migrationSQL(newMigrator(oldData, down, types), down))
+
+	return writeText(out, sb.String())
+}
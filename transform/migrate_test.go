@@ -0,0 +1,170 @@
+package transform
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+const migrateOld = `schemas:
+  - name: app
+    tables:
+      - name: doc
+        columns:
+          - {na: doc_id, ty: INT, id: Y, nu: Y}
+          - {na: src, ty: CHAR(3), enum: [A, B]}
+          - {na: kind, ty: CHAR(1), enum: [X, Y]}
+          - {na: code, ty: VARCHAR(10), un: Y}
+          - {na: ref, ty: VARCHAR(10)}
+`
+
+const migrateNew = `schemas:
+  - name: app
+    tables:
+      - name: doc
+        columns:
+          - {na: doc_id, ty: INT, id: Y, nu: Y}
+          - {na: kind, ty: CHAR(1), enum: [X, Y, Z]}
+          - {na: code, ty: VARCHAR(10)}
+          - {na: ref, ty: VARCHAR(10), un: Y}
+          - {na: tag, ty: VARCHAR(10), un: Y}
+        checks:
+          - {name: ck_doc_ref, expr: "ref <> ''"}
+  - name: audit
+    tables:
+      - name: log
+        columns:
+          - {na: log_id, ty: INT, id: Y, nu: Y}
+          - {na: msg, ty: VARCHAR(10), un: Y}
+`
+
+func TestDiffChecksAndUniques(t *testing.T) {
+	changes := Diff(readTestYml(t, migrateOld), readTestYml(t, migrateNew))
+	got := lo.FilterMap(changes, func(c Change, _ int) (string, bool) {
+		return c.Kind + " " + c.Object + " " + c.Name + " " + c.Class, c.Object == ObjectCheck || c.Object == ObjectUnique
+	})
+	want := []string{
+		"add unique uq_doc_ref narrowing",
+		"add unique uq_doc_tag narrowing",
+		"drop unique uq_doc_code widening",
+		"alter check ck_doc_kind narrowing",
+		"add check ck_doc_ref narrowing",
+		"drop check ck_doc_src widening",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("changes =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	// the checks and uniques of the added table are created with the table
+	if lo.ContainsBy(changes, func(c Change) bool { return c.Table == "log" && c.Object != ObjectTable }) {
+		t.Error("unexpected constraint changes of the added table")
+	}
+}
+
+func TestWriteMigration(t *testing.T) {
+	oldData, newData := readTestYml(t, migrateOld), readTestYml(t, migrateNew)
+	tests := []struct {
+		dialect string
+		up      []string // the statements of the up script in order
+	}{
+		{DialectPostgres, []string{
+			"DROP CONSTRAINT IF EXISTS ck_doc_kind;",
+			"DROP CONSTRAINT IF EXISTS ck_doc_src;",
+			"DROP CONSTRAINT IF EXISTS doc_code_key;",
+			"CREATE SCHEMA IF NOT EXISTS audit;\nCREATE TABLE IF NOT EXISTS audit.log (",
+			"msg VARCHAR(10) UNIQUE,",
+			"ALTER TABLE app.doc ADD COLUMN IF NOT EXISTS tag VARCHAR(10);",
+			"ALTER TABLE app.doc DROP COLUMN IF EXISTS src;",
+			"ALTER TABLE app.doc ADD CONSTRAINT doc_ref_key UNIQUE (ref);",
+			"ALTER TABLE app.doc ADD CONSTRAINT doc_tag_key UNIQUE (tag);",
+			"ALTER TABLE app.doc ADD CONSTRAINT ck_doc_kind CHECK (kind IN ('X', 'Y', 'Z'));",
+			"ALTER TABLE app.doc ADD CONSTRAINT ck_doc_ref CHECK (ref <> '');",
+		}},
+		{DialectMSSQL, []string{
+			"IF OBJECT_ID(N'ck_doc_kind', N'C') IS NOT NULL ALTER TABLE doc DROP CONSTRAINT ck_doc_kind;",
+			"IF OBJECT_ID(N'ck_doc_src', N'C') IS NOT NULL ALTER TABLE doc DROP CONSTRAINT ck_doc_src;",
+			"IF OBJECT_ID(N'uqdoccode', N'UQ') IS NOT NULL ALTER TABLE doc DROP CONSTRAINT uqdoccode;",
+			"CONSTRAINT uqlogmsg UNIQUE (msg)",
+			"ALTER TABLE doc ADD tag VARCHAR(10);",
+			"ALTER TABLE doc DROP COLUMN src;",
+			"ALTER TABLE doc ADD CONSTRAINT uqdocref UNIQUE (ref);",
+			"ALTER TABLE doc ADD CONSTRAINT uqdoctag UNIQUE (tag);",
+			"ALTER TABLE doc ADD CONSTRAINT ck_doc_kind CHECK (kind IN ('X', 'Y', 'Z'));",
+			"ALTER TABLE doc ADD CONSTRAINT ck_doc_ref CHECK (ref <> '');",
+		}},
+		{DialectMariaDB, []string{
+			"ALTER TABLE IF EXISTS doc DROP CONSTRAINT IF EXISTS ck_doc_kind;",
+			"ALTER TABLE IF EXISTS doc DROP CONSTRAINT IF EXISTS ck_doc_src;",
+			"DROP INDEX IF EXISTS uq_doc_code ON doc;",
+			"CONSTRAINT uq_log_msg UNIQUE (msg)",
+			"ALTER TABLE doc ADD COLUMN IF NOT EXISTS tag VARCHAR(10);",
+			"ALTER TABLE doc DROP COLUMN IF EXISTS src;",
+			"CREATE UNIQUE INDEX IF NOT EXISTS uq_doc_ref ON doc (ref);",
+			"CREATE UNIQUE INDEX IF NOT EXISTS uq_doc_tag ON doc (tag);",
+			"ALTER TABLE doc ADD CONSTRAINT ck_doc_kind CHECK (kind IN ('X', 'Y', 'Z'));",
+			"ALTER TABLE doc ADD CONSTRAINT ck_doc_ref CHECK (ref <> '');",
+		}},
+		{DialectSqlite, []string{
+			"-- SQLite cannot drop the check ck_doc_kind of the table doc",
+			"-- SQLite cannot drop the check ck_doc_src of the table doc",
+			"DROP INDEX IF EXISTS uq_doc_code;",
+			"msg TEXT UNIQUE",
+			"ALTER TABLE doc ADD COLUMN tag TEXT;",
+			"ALTER TABLE doc DROP COLUMN src;",
+			"CREATE UNIQUE INDEX IF NOT EXISTS uq_doc_ref ON doc (ref);",
+			"CREATE UNIQUE INDEX IF NOT EXISTS uq_doc_tag ON doc (tag);",
+			"-- SQLite cannot add the check ck_doc_kind to the table doc",
+			"-- SQLite cannot add the check ck_doc_ref to the table doc",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			script := readTestOutput(t, "migration.sql", func(out string) error {
+				return WriteMigration(oldData, newData, tt.dialect, nil, out)
+			})
+			up, down, found := strings.Cut(script, "-- +goose Down")
+			if !found {
+				t.Fatalf("missing down script:\n%s", script)
+			}
+			assertInOrder(t, up, tt.up...)
+			// the down script reverts the constraints of the dropped column
+			if !strings.Contains(down, "ck_doc_src") {
+				t.Errorf("down script does not restore ck_doc_src:\n%s", down)
+			}
+		})
+	}
+}
+
+func TestWriteMigrationExistingSchema(t *testing.T) {
+	oldData := readTestYml(t, migrateOld)
+	newData := readTestYml(t, strings.Replace(migrateOld, "    tables:\n", `    tables:
+      - name: tag
+        columns:
+          - {na: tag_id, ty: INT, id: Y, nu: Y}
+`, 1))
+	script := readTestOutput(t, "migration.sql", func(out string) error {
+		return WriteMigration(oldData, newData, DialectPostgres, nil, out)
+	})
+	if strings.Contains(script, "CREATE SCHEMA") {
+		t.Errorf("unexpected CREATE SCHEMA of the existing schema:\n%s", script)
+	}
+	assertInOrder(t, script, "CREATE TABLE IF NOT EXISTS app.tag (", "DROP TABLE IF EXISTS app.tag;")
+}
+
+func TestWriteMigrationUnsupportedDialect(t *testing.T) {
+	if err := WriteMigration(&DataDef{}, &DataDef{}, "oracle", nil, ""); err == nil {
+		t.Error("expected an error for the unsupported dialect")
+	}
+}
+
+func TestWriteMigrationMariadbIndexes(t *testing.T) {
+	oldData, newData := readTestYml(t, migrateOld), readTestYml(t, indexesYml)
+	script := readTestOutput(t, "migration.sql", func(out string) error {
+		return WriteMigration(oldData, newData, DialectMariaDB, nil, out)
+	})
+	assertInOrder(t, script,
+		"CREATE INDEX IF NOT EXISTS ix_doc_code ON doc (code);\n"+
+			"-- ix_doc_code included columns skipped, INCLUDE is not supported: title",
+		"-- ix_doc_title skipped, partial index is not supported: WHERE code IS NOT NULL",
+	)
+}
@@ -1,39 +1,309 @@
 package transform
 
+import (
+	"fmt"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
 type DataDef struct {
-	Fixed    []Column    `yaml:"fixed,omitempty"`
-	OutFixed []OutColumn `yaml:"out_fixed,omitempty"`
-	Schemas  []Schema    `yaml:"schemas,omitempty"`
+	Include  []string          `yaml:"include,omitempty"` // files or patterns relative to the file
+	Domains  map[string]Column `yaml:"domains,omitempty"`
+	Mixins   []Mixin           `yaml:"mixins,omitempty"`
+	Fixed    []Column          `yaml:"fixed,omitempty"`
+	OutFixed []OutColumn       `yaml:"out_fixed,omitempty"`
+	Schemas  []Schema          `yaml:"schemas,omitempty"`
+}
+
+// Mixin is a named group of columns appended to the tables using it.
+type Mixin struct {
+	Name    string   `yaml:"name,omitempty"`
+	Columns []Column `yaml:"columns,omitempty"`
+	Pos     Position `yaml:"-"`
 }
 
 type Schema struct {
-	Name   string  `yaml:"name,omitempty" default:"Schema"`
-	Desc   string  `yaml:"description,omitempty"`
-	Tables []Table `yaml:"tables,omitempty"`
+	Name   string   `yaml:"name,omitempty" default:"Schema"`
+	Desc   string   `yaml:"description,omitempty"`
+	Tables []Table  `yaml:"tables,omitempty"`
+	Pos    Position `yaml:"-"`
 }
 
 type Table struct {
-	Name       string      `yaml:"name,omitempty"`
-	Title      string      `yaml:"title,omitempty"`
-	Desc       string      `yaml:"desc,omitempty"`
-	Columns    []Column    `yaml:"columns,omitempty"`
-	OutColumns []OutColumn `yaml:"out_columns,omitempty"`
+	Name         string       `yaml:"name,omitempty"`
+	Title        string       `yaml:"title,omitempty"`
+	Desc         string       `yaml:"desc,omitempty"`
+	PrimaryKey   []string     `yaml:"primary_key,flow,omitempty"`
+	ForeignKeys  []ForeignKey `yaml:"foreign_keys,omitempty"`
+	Indexes      []Index      `yaml:"indexes,omitempty"`
+	Checks       []Check      `yaml:"checks,omitempty"`
+	Use          []string     `yaml:"use,flow,omitempty"`      // names of the mixins
+	ExcludeFixed bool         `yaml:"exclude_fixed,omitempty"` // true if the fixed columns are not appended
+	Columns      []Column     `yaml:"columns,omitempty"`
+	OutColumns   []OutColumn  `yaml:"out_columns,omitempty"`
+	Pos          Position     `yaml:"-"`
+}
+
+// ForeignKey is a foreign key of the table, the columns reference the
+// columns of the referenced table in order. The primary key of the referenced
+// table is referenced if RefColumns is not defined.
+type ForeignKey struct {
+	Name       string   `yaml:"name,omitempty"`
+	Columns    []string `yaml:"columns,flow,omitempty"`
+	RefTable   string   `yaml:"ref_table,omitempty"`
+	RefColumns []string `yaml:"ref_columns,flow,omitempty"`
+	OnDelete   string   `yaml:"on_delete,omitempty"` // CASCADE, SET NULL, SET DEFAULT, RESTRICT or NO ACTION
+	OnUpdate   string   `yaml:"on_update,omitempty"`
+	Pos        Position `yaml:"-"`
 }
 
 type Column struct {
-	Name        string `yaml:"na,omitempty"`
-	DataType    string `yaml:"ty,omitempty"`
-	Identity    string `yaml:"id,omitempty"`
-	NotNull     string `yaml:"nu,omitempty" default:"N"`
-	Unique      string `yaml:"un,omitempty"`
-	Value       string `yaml:"va,omitempty"`
-	ForeignKey  string `yaml:"fk,omitempty"`
-	Cardinality string `yaml:"cd,omitempty"`
-	Title       string `yaml:"tt,omitempty"`
-	Index       string `yaml:"in,omitempty"`
-	Desc        string `yaml:"dc,omitempty"`
+	Name        string   `yaml:"na,omitempty"`
+	DataType    string   `yaml:"ty,omitempty"`
+	Identity    string   `yaml:"id,omitempty"`
+	NotNull     string   `yaml:"nu,omitempty" default:"N"`
+	Unique      string   `yaml:"un,omitempty"`
+	Value       string   `yaml:"va,omitempty"`
+	ForeignKey  string   `yaml:"fk,omitempty"`
+	Cardinality string   `yaml:"cd,omitempty"`
+	Title       string   `yaml:"tt,omitempty"`
+	Index       string   `yaml:"in,omitempty"`
+	Enum        Enum     `yaml:"enum,omitempty"`
+	Desc        string   `yaml:"dc,omitempty"`
+	Pos         Position `yaml:"-"`
+	parsedType  *Type    // the parsed data type, see Type
 }
 
 type OutColumn struct {
 	Value Column `yaml:"_column_values,flow,omitempty"`
 }
+
+// Index is an index of the table, the columns are in the order of the index
+// with an optional sort order, e.g. [tag_id, created_at DESC].
+type Index struct {
+	Name    string   `yaml:"name,omitempty"`
+	Columns []string `yaml:"columns,flow,omitempty"`
+	Unique  bool     `yaml:"unique,omitempty"`
+	Where   string   `yaml:"where,omitempty"`        // predicate of the partial (filtered) index
+	Include []string `yaml:"include,flow,omitempty"` // non-key columns included in the index
+	Pos     Position `yaml:"-"`
+}
+
+// Check is a check constraint of the table.
+type Check struct {
+	Name string   `yaml:"name,omitempty"`
+	Expr string   `yaml:"expr,omitempty"`
+	Pos  Position `yaml:"-"`
+}
+
+// EnumValue is an allowed value of the column with an optional label.
+type EnumValue struct {
+	Value string
+	Label string
+}
+
+// Enum is the allowed values of the column, it is written as a list of values,
+// e.g. [A, B], or a mapping of the values and labels to keep the order, e.g.
+// { COM: common, PRE: pre }.
+type Enum []EnumValue
+
+// Values returns the values without the labels.
+func (e Enum) Values() []string {
+	return lo.Map(e, func(v EnumValue, _ int) string { return v.Value })
+}
+
+// UnmarshalYAML reads the list of values or the mapping of values and labels.
+func (e *Enum) UnmarshalYAML(node *yaml.Node) error {
+	*e = nil
+	switch node.Kind {
+	case yaml.SequenceNode:
+		for _, item := range node.Content {
+			*e = append(*e, EnumValue{Value: item.Value})
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			*e = append(*e, EnumValue{Value: node.Content[i].Value, Label: node.Content[i+1].Value})
+		}
+	default:
+		return fmt.Errorf("line %d: enum must be a list of values or a mapping of values and labels", node.Line)
+	}
+	return nil
+}
+
+// MarshalYAML writes the list of values if there is no label, otherwise the
+// mapping of values and labels.
+func (e Enum) MarshalYAML() (interface{}, error) {
+	scalar := func(v string) *yaml.Node {
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+	}
+	if lo.EveryBy(e, func(v EnumValue) bool { return v.Label == "" }) {
+		node := &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle}
+		for _, v := range e {
+			node.Content = append(node.Content, scalar(v.Value))
+		}
+		return node, nil
+	}
+	node := &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+	for _, v := range e {
+		node.Content = append(node.Content, scalar(v.Value), scalar(v.Label))
+	}
+	return node, nil
+}
+
+// ColumnNames returns the column names of the index without the sort order.
+func (ix Index) ColumnNames() []string {
+	names := make([]string, 0, len(ix.Columns))
+	for _, column := range ix.Columns {
+		name, _ := splitIndexColumn(column)
+		names = append(names, name)
+	}
+	return names
+}
+
+// FixedColumns returns the fixed columns appended to the table, which is
+// empty if the table excludes the fixed columns.
+func (t Table) FixedColumns(fixed []Column) []Column {
+	if t.ExcludeFixed {
+		return nil
+	}
+	return fixed
+}
+
+// PrimaryKeyColumns returns the column names of the primary key, which is the
+// primary_key of the table, or the identity columns (including the fixed
+// columns) if primary_key is not defined.
+func (t Table) PrimaryKeyColumns(fixed []Column) []string {
+	if len(t.PrimaryKey) > 0 {
+		return t.PrimaryKey
+	}
+	names := make([]string, 0)
+	for _, column := range append(append([]Column{}, t.Columns...), t.FixedColumns(fixed)...) {
+		if isYes(column.Identity) {
+			names = append(names, column.Name)
+		}
+	}
+	return names
+}
+
+// ForeignKeys returns the foreign keys of the table, which are the foreign
+// keys of the columns (fk: table.column, including the fixed columns) followed
+// by the foreign keys of the table. The referenced columns are resolved to the
+// primary key of the referenced table if not defined.
+func (d DataDef) ForeignKeys(table Table) []ForeignKey {
+	columns := tableColumns(&d, table)
+	result := make([]ForeignKey, 0)
+	for _, column := range columns {
+		if rtable, rcolumn, ok := splitForeignKey(column.ForeignKey); ok {
+			result = append(result, ForeignKey{Columns: []string{column.Name}, RefTable: rtable, RefColumns: []string{rcolumn}, Pos: column.Pos})
+		}
+	}
+	for _, fk := range table.ForeignKeys {
+		result = append(result, d.resolveForeignKey(fk))
+	}
+	return result
+}
+
+// Checks returns the check constraints of the table, which are the allowed
+// values of the enum columns (including the fixed columns) followed by the
+// checks of the table. The names are ck_<table>_<column> for the enum columns
+// and ck_<table>_<n> for the checks without name.
+func (d DataDef) Checks(table Table) []Check {
+	return tableChecks(&d, table, func(name string) string { return name })
+}
+
+// resolveForeignKey returns the foreign key referencing the primary key of the
+// referenced table if the referenced columns are not defined.
+func (d DataDef) resolveForeignKey(fk ForeignKey) ForeignKey {
+	if len(fk.RefColumns) == 0 {
+		if rtable, found := findTable(&d, fk.RefTable); found {
+			fk.RefColumns = rtable.PrimaryKeyColumns(d.Fixed)
+		}
+	}
+	return fk
+}
+
+// Position is the location of an element in the definition file.
+type Position struct {
+	File string `json:"file,omitempty"`
+	Line int    `json:"line,omitempty"`
+	Col  int    `json:"col,omitempty"`
+}
+
+// String returns the position in the format of file:line:col.
+func (p Position) String() string {
+	if p.Line == 0 {
+		return p.File
+	}
+	return fmt.Sprintf("%s:%d:%d", p.File, p.Line, p.Col)
+}
+
+// UnmarshalYAML keeps the position of the schema in the yaml file.
+func (s *Schema) UnmarshalYAML(node *yaml.Node) error {
+	type plain Schema
+	if err := node.Decode((*plain)(s)); err != nil {
+		return err
+	}
+	s.Pos = Position{Line: node.Line, Col: node.Column}
+	return nil
+}
+
+// UnmarshalYAML keeps the position of the mixin in the yaml file.
+func (m *Mixin) UnmarshalYAML(node *yaml.Node) error {
+	type plain Mixin
+	if err := node.Decode((*plain)(m)); err != nil {
+		return err
+	}
+	m.Pos = Position{Line: node.Line, Col: node.Column}
+	return nil
+}
+
+// UnmarshalYAML keeps the position of the table in the yaml file.
+func (t *Table) UnmarshalYAML(node *yaml.Node) error {
+	type plain Table
+	if err := node.Decode((*plain)(t)); err != nil {
+		return err
+	}
+	t.Pos = Position{Line: node.Line, Col: node.Column}
+	return nil
+}
+
+// UnmarshalYAML keeps the position of the foreign key in the yaml file.
+func (fk *ForeignKey) UnmarshalYAML(node *yaml.Node) error {
+	type plain ForeignKey
+	if err := node.Decode((*plain)(fk)); err != nil {
+		return err
+	}
+	fk.Pos = Position{Line: node.Line, Col: node.Column}
+	return nil
+}
+
+// UnmarshalYAML keeps the position of the index in the yaml file.
+func (ix *Index) UnmarshalYAML(node *yaml.Node) error {
+	type plain Index
+	if err := node.Decode((*plain)(ix)); err != nil {
+		return err
+	}
+	ix.Pos = Position{Line: node.Line, Col: node.Column}
+	return nil
+}
+
+// UnmarshalYAML keeps the position of the check in the yaml file.
+func (c *Check) UnmarshalYAML(node *yaml.Node) error {
+	type plain Check
+	if err := node.Decode((*plain)(c)); err != nil {
+		return err
+	}
+	c.Pos = Position{Line: node.Line, Col: node.Column}
+	return nil
+}
+
+// UnmarshalYAML keeps the position of the column in the yaml file.
+func (c *Column) UnmarshalYAML(node *yaml.Node) error {
+	type plain Column
+	if err := node.Decode((*plain)(c)); err != nil {
+		return err
+	}
+	c.Pos = Position{Line: node.Line, Col: node.Column}
+	return nil
+}
@@ -0,0 +1,177 @@
+package transform
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// mssqlReservedWords are the reserved keywords of SQL Server, which must be
+// delimited when used as identifier.
+var mssqlReservedWords = []string{
+	"add", "all", "alter", "and", "any", "as", "asc", "authorization", "backup", "begin", "between", "break",
+	"browse", "bulk", "by", "cascade", "case", "check", "checkpoint", "close", "clustered", "coalesce",
+	"collate", "column", "commit", "compute", "constraint", "contains", "continue", "convert", "create",
+	"cross", "current", "current_date", "current_time", "current_timestamp", "current_user", "cursor",
+	"database", "deallocate", "declare", "default", "delete", "deny", "desc", "disk", "distinct", "distributed",
+	"double", "drop", "dump", "else", "end", "errlvl", "escape", "except", "exec", "execute", "exists", "exit",
+	"external", "fetch", "file", "fillfactor", "for", "foreign", "freetext", "from", "full", "function",
+	"goto", "grant", "group", "having", "holdlock", "identity", "identitycol", "if", "in", "index", "inner",
+	"insert", "intersect", "into", "is", "join", "key", "kill", "left", "like", "lineno", "merge", "national",
+	"nocheck", "nonclustered", "not", "null", "nullif", "of", "off", "offsets", "on", "open", "option", "or",
+	"order", "outer", "over", "percent", "pivot", "plan", "precision", "primary", "print", "proc",
+	"procedure", "public", "raiserror", "read", "readtext", "reconfigure", "references", "replication",
+	"restore", "restrict", "return", "revert", "revoke", "right", "rollback", "rowcount", "rowguidcol", "rule",
+	"save", "schema", "select", "session_user", "set", "setuser", "shutdown", "some", "statistics",
+	"system_user", "table", "tablesample", "textsize", "then", "to", "top", "tran", "transaction", "trigger",
+	"truncate", "union", "unique", "unpivot", "update", "updatetext", "use", "user", "values", "varying",
+	"view", "waitfor", "when", "where", "while", "with", "writetext",
+}
+
+// mssqlFkName returns the name of the foreign key, the default name is
+// fk<table><columns> as the mssql templates.
+func mssqlFkName(table string, fk ForeignKey) string {
+	return lo.Ternary(fk.Name != "", fk.Name, "fk"+table+strings.Join(fk.Columns, ""))
+}
+
+// mssqlIndexName returns the name of the index, the default name is
+// idx<table><columns> as the mssql templates.
+func mssqlIndexName(table string, ix Index) string {
+	return lo.Ternary(ix.Name != "", ix.Name, "idx"+table+strings.Join(ix.ColumnNames(), ""))
+}
+
+// mssqlUniqueName returns the name of the unique constraint of the column,
+// which is uq<table><column> in the style of the mssql templates.
+func mssqlUniqueName(table string, column string) string {
+	return "uq" + table + column
+}
+
+// mssqlColumn returns the column definition of CREATE TABLE and ADD, the
+// identifiers are not quoted as the mssql templates.
+func mssqlColumn(column Column, types TypeMappings) string {
+	line := fmt.Sprintf("%s %s", column.Name, MapType(column.Type(), DialectMSSQL, types))
+	if isYes(column.Identity) {
+		line += " IDENTITY(1,1)"
+	}
+	if column.Value != "" {
+		line += " DEFAULT " + sqlDefault(column.Value)
+	}
+	if isYes(column.NotNull) {
+		line += " NOT NULL"
+	}
+	return line
+}
+
+// mssqlDropDefault returns the statements dropping the default constraint of
+// the column, the constraint name is generated by SQL Server if not named.
+func mssqlDropDefault(table string, column string) string {
+	return fmt.Sprintf(`-- +goose StatementBegin
+DECLARE @df sysname = (SELECT name FROM sys.default_constraints WHERE parent_object_id = OBJECT_ID(N'%s') AND parent_column_id = COLUMNPROPERTY(OBJECT_ID(N'%s'), N'%s', 'ColumnId'));
+IF @df IS NOT NULL EXEC(N'ALTER TABLE %s DROP CONSTRAINT ' + @df);
+-- +goose StatementEnd
+`, table, table, column, table)
+}
+
+// mssqlMigrator writes the SQL Server statements of the changes, types are
+// the type mappings.
+type mssqlMigrator struct {
+	types TypeMappings
+}
+
+func (m mssqlMigrator) createTable(c Change) string {
+	table := c.New.Table
+	lines := lo.Map(table.Columns, func(column Column, _ int) string { return "      " + mssqlColumn(column, m.types) })
+	if len(table.PrimaryKey) > 0 {
+		lines = append(lines, fmt.Sprintf("      CONSTRAINT pk%s%s PRIMARY KEY (%s)",
+			table.Name, strings.Join(table.PrimaryKey, ""), strings.Join(table.PrimaryKey, ", ")))
+	}
+	for _, column := range table.Columns {
+		if isYes(column.Unique) {
+			lines = append(lines, fmt.Sprintf("      CONSTRAINT %s UNIQUE (%s)", mssqlUniqueName(table.Name, column.Name), column.Name))
+		}
+	}
+	for _, check := range tableChecks(&DataDef{}, table, func(name string) string { return name }) {
+		lines = append(lines, fmt.Sprintf("      CONSTRAINT %s CHECK (%s)", check.Name, check.Expr))
+	}
+	return fmt.Sprintf("IF OBJECT_ID(N'%s', N'U') IS NULL\nBEGIN\nCREATE TABLE %s (\n%s\n)\nEND;\n",
+		table.Name, table.Name, strings.Join(lines, ",\n"))
+}
+
+func (m mssqlMigrator) dropTable(c Change) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", c.Table)
+}
+
+func (m mssqlMigrator) addColumn(c Change) string {
+	return fmt.Sprintf("IF COL_LENGTH(N'%s', N'%s') IS NULL ALTER TABLE %s ADD %s;\n", c.Table, c.Name, c.Table, mssqlColumn(c.New.Column, m.types))
+}
+
+func (m mssqlMigrator) alterColumn(c Change) string {
+	var sb strings.Builder
+	column := c.New.Column
+	// the default is dropped before altering the column and added after it
+	if lo.Contains(c.Fields, FieldDefault) {
+		sb.WriteString(mssqlDropDefault(c.Table, c.Name))
+	}
+	if lo.Contains(c.Fields, FieldType) || lo.Contains(c.Fields, FieldNotNull) {
+		sb.WriteString(fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s %s %s;\n",
+			c.Table, c.Name, MapType(column.Type(), DialectMSSQL, m.types), lo.Ternary(isYes(column.NotNull), "NOT NULL", "NULL")))
+	}
+	if lo.Contains(c.Fields, FieldDefault) && column.Value != "" {
+		sb.WriteString(fmt.Sprintf("ALTER TABLE %s ADD DEFAULT %s FOR %s;\n", c.Table, sqlDefault(column.Value), c.Name))
+	}
+	return sb.String()
+}
+
+func (m mssqlMigrator) dropColumn(c Change) string {
+	return mssqlDropDefault(c.Table, c.Name) +
+		fmt.Sprintf("IF COL_LENGTH(N'%s', N'%s') IS NOT NULL ALTER TABLE %s DROP COLUMN %s;\n", c.Table, c.Name, c.Table, c.Name)
+}
+
+func (m mssqlMigrator) createIndex(c Change) string {
+	ix := c.New.Index
+	name := mssqlIndexName(c.Table, ix)
+	sql := fmt.Sprintf("IF NOT EXISTS (SELECT * FROM sys.indexes WHERE name = '%s' AND object_id = OBJECT_ID('%s')) CREATE%s INDEX %s ON %s (%s)",
+		name, c.Table, lo.Ternary(ix.Unique, " UNIQUE", ""), name, c.Table, strings.Join(ix.Columns, ", "))
+	if len(ix.Include) > 0 {
+		sql += fmt.Sprintf(" INCLUDE (%s)", strings.Join(ix.Include, ", "))
+	}
+	if ix.Where != "" {
+		sql += " WHERE " + ix.Where
+	}
+	return sql + ";\n"
+}
+
+func (m mssqlMigrator) dropIndex(c Change) string {
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s ON %s;\n", mssqlIndexName(c.Table, c.Old.Index), c.Table)
+}
+
+func (m mssqlMigrator) addForeignKey(c Change) string {
+	fk := c.New.ForeignKey
+	name := mssqlFkName(c.Table, fk)
+	return fmt.Sprintf("IF OBJECT_ID(N'%s', N'F') IS NULL ALTER TABLE %s ADD CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)%s;\n",
+		name, c.Table, name, strings.Join(fk.Columns, ", "), fk.RefTable, strings.Join(fk.RefColumns, ", "), fkActionClause(fk))
+}
+
+func (m mssqlMigrator) dropForeignKey(c Change) string {
+	name := mssqlFkName(c.Table, c.Old.ForeignKey)
+	return fmt.Sprintf("IF OBJECT_ID(N'%s', N'F') IS NOT NULL ALTER TABLE %s DROP CONSTRAINT %s;\n", name, c.Table, name)
+}
+
+func (m mssqlMigrator) addCheck(c Change) string {
+	return fmt.Sprintf("IF OBJECT_ID(N'%s', N'C') IS NULL ALTER TABLE %s ADD CONSTRAINT %s CHECK (%s);\n", c.Name, c.Table, c.Name, c.New.Check.Expr)
+}
+
+func (m mssqlMigrator) dropCheck(c Change) string {
+	return fmt.Sprintf("IF OBJECT_ID(N'%s', N'C') IS NOT NULL ALTER TABLE %s DROP CONSTRAINT %s;\n", c.Name, c.Table, c.Name)
+}
+
+func (m mssqlMigrator) addUnique(c Change) string {
+	name := mssqlUniqueName(c.Table, c.New.Column.Name)
+	return fmt.Sprintf("IF OBJECT_ID(N'%s', N'UQ') IS NULL ALTER TABLE %s ADD CONSTRAINT %s UNIQUE (%s);\n", name, c.Table, name, c.New.Column.Name)
+}
+
+func (m mssqlMigrator) dropUnique(c Change) string {
+	name := mssqlUniqueName(c.Table, c.Old.Column.Name)
+	return fmt.Sprintf("IF OBJECT_ID(N'%s', N'UQ') IS NOT NULL ALTER TABLE %s DROP CONSTRAINT %s;\n", name, c.Table, name)
+}
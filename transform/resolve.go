@@ -0,0 +1,90 @@
+package transform
+
+import (
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// DomainPrefix is the prefix of the data type referring to a domain, e.g. $money.
+const DomainPrefix = "$"
+
+// domainName returns the domain name of the data type, or empty if the data
+// type is not a domain reference.
+func domainName(dataType string) string {
+	if !strings.HasPrefix(dataType, DomainPrefix) {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(dataType, DomainPrefix))
+}
+
+// applyDomain returns the column with the domain expanded, the data type is
+// replaced by the type of the domain and the empty properties are set by the
+// domain. The column is returned as is if the domain is not found.
+func applyDomain(column Column, domains map[string]Column) Column {
+	name := domainName(column.DataType)
+	if name == "" {
+		return column
+	}
+	domain, found := domains[name]
+	if !found {
+		return column
+	}
+	column.DataType = domain.DataType
+	or := func(value string, def string) string { return lo.Ternary(value != "", value, def) }
+	column.Identity = or(column.Identity, domain.Identity)
+	column.NotNull = or(column.NotNull, domain.NotNull)
+	column.Unique = or(column.Unique, domain.Unique)
+	column.Value = or(column.Value, domain.Value)
+	column.ForeignKey = or(column.ForeignKey, domain.ForeignKey)
+	column.Cardinality = or(column.Cardinality, domain.Cardinality)
+	column.Title = or(column.Title, domain.Title)
+	column.Index = or(column.Index, domain.Index)
+	column.Desc = or(column.Desc, domain.Desc)
+	if len(column.Enum) == 0 {
+		column.Enum = domain.Enum
+	}
+	return column
+}
+
+// Resolve returns the flattened copy of the definition, the domains of the
+// columns are expanded and the columns of the mixins are appended to the
+// tables using them. The result has no domains and mixins, the writers only
+// see the plain columns with the parsed data types. The unknown domains and
+// mixins are reported by Verify and left as is.
+func Resolve(data *DataDef) *DataDef {
+	expand := func(columns []Column) []Column {
+		return lo.Map(columns, func(c Column, _ int) Column {
+			c = applyDomain(c, data.Domains)
+			t := parseColumnType(c.DataType)
+			c.parsedType = &t
+			return c
+		})
+	}
+	mixins := make(map[string][]Column)
+	for _, mixin := range data.Mixins {
+		if _, found := mixins[mixin.Name]; !found {
+			mixins[mixin.Name] = expand(mixin.Columns)
+		}
+	}
+
+	d := &DataDef{
+		Fixed:   expand(data.Fixed),
+		Schemas: make([]Schema, 0, len(data.Schemas)),
+	}
+	for _, schema := range data.Schemas {
+		tables := make([]Table, 0, len(schema.Tables))
+		for _, table := range schema.Tables {
+			columns := expand(table.Columns)
+			for _, name := range table.Use {
+				columns = append(columns, mixins[name]...)
+			}
+			table.Columns = columns
+			table.Use = nil
+			tables = append(tables, table)
+		}
+		schema.Tables = tables
+		d.Schemas = append(d.Schemas, schema)
+	}
+	return d
+}
@@ -0,0 +1,113 @@
+package transform
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+func TestResolve(t *testing.T) {
+	data := readTestYml(t, `domains:
+  money: { ty: "DECIMAL(12,2)", nu: Y, va: "0", dc: amount of money }
+  code: { ty: VARCHAR(10), enum: [A, B] }
+mixins:
+  - name: audit
+    columns:
+      - { na: created_by, ty: INT, nu: Y }
+      - { na: created_at, ty: DATETIME, nu: Y, va: CURRENT_TIMESTAMP }
+  - name: soft_delete
+    columns:
+      - { na: deleted, ty: $code }
+fixed:
+  - { na: recver, ty: INT, nu: Y, va: "0" }
+schemas:
+  - name: app
+    tables:
+      - name: doc
+        use: [audit, soft_delete]
+        columns:
+          - { na: doc_id, ty: INT, id: Y, nu: Y }
+          - { na: price, ty: $money }
+          - { na: cost, ty: $money, nu: N, dc: cost of the document }
+      - name: lookup
+        exclude_fixed: true
+        columns:
+          - { na: code, ty: $code, enum: [X] }
+`)
+	// the properties of the column override the ones of the domain
+	want := readTestYml(t, `fixed:
+  - { na: recver, ty: INT, nu: Y, va: "0" }
+schemas:
+  - name: app
+    tables:
+      - name: doc
+        columns:
+          - { na: doc_id, ty: INT, id: Y, nu: Y }
+          - { na: price, ty: "DECIMAL(12,2)", nu: Y, va: "0", dc: amount of money }
+          - { na: cost, ty: "DECIMAL(12,2)", nu: N, va: "0", dc: cost of the document }
+          - { na: created_by, ty: INT, nu: Y }
+          - { na: created_at, ty: DATETIME, nu: Y, va: CURRENT_TIMESTAMP }
+          - { na: deleted, ty: VARCHAR(10), enum: [A, B] }
+      - name: lookup
+        exclude_fixed: true
+        columns:
+          - { na: code, ty: VARCHAR(10), enum: [X] }
+`)
+	got := Resolve(data)
+	want = Resolve(want)
+	clearPositions(got)
+	clearPositions(want)
+	if !reflect.DeepEqual(got, want) {
+		gotYml, _ := yaml.Marshal(got)
+		wantYml, _ := yaml.Marshal(want)
+		t.Errorf("resolved definition differs\ngot:\n%s\nwant:\n%s", gotYml, wantYml)
+	}
+
+	// the fixed columns are appended except to the excluded tables
+	for i, want := range []string{"doc_id,price,cost,created_by,created_at,deleted,recver", "code"} {
+		table := got.Schemas[0].Tables[i]
+		names := lo.Map(tableColumns(got, table), func(c Column, _ int) string { return c.Name })
+		if strings.Join(names, ",") != want {
+			t.Errorf("columns of %s = %v, want %s", table.Name, names, want)
+		}
+	}
+	if findings := VerifyStructure(data); len(findings) > 0 {
+		t.Errorf("unexpected findings %v", findings)
+	}
+}
+
+func TestVerifyReferences(t *testing.T) {
+	data := readTestYml(t, `domains:
+  money: { ty: "DECIMAL(12,2)" }
+  price: { ty: $money }
+mixins:
+  - name: audit
+    columns:
+      - { na: created_by, ty: $user }
+  - name: audit
+    columns:
+      - { na: created_at, ty: DATETIME }
+schemas:
+  - name: app
+    tables:
+      - name: doc
+        use: [audit, history]
+        columns:
+          - { na: doc_id, ty: INT, id: Y, nu: Y }
+          - { na: amount, ty: $amount }
+`)
+	got := strings.Join(lo.Map(verifyReferences(data), func(f Finding, _ int) string { return f.Rule + " " + f.Message }), "\n")
+	want := strings.Join([]string{
+		"domain domain 'price' cannot refer to other domain",
+		"domain domain 'user' cannot be found",
+		"mixin duplicate mixin 'audit'",
+		"mixin mixin 'history' cannot be found",
+		"domain domain 'amount' cannot be found",
+	}, "\n")
+	if got != want {
+		t.Errorf("findings =\n%s\nwant\n%s", got, want)
+	}
+}
@@ -0,0 +1,163 @@
+package transform
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+)
+
+// ruleDescriptions are the descriptions of the rules of the findings.
+var ruleDescriptions = map[string]string{
+	"column-name":     "The column has a name.",
+	"column-type":     "The column has a valid data type.",
+	"check":           "The checks have the expressions and unique names, the enum values are unique.",
+	"foreign-key":     "The foreign key references the existing table and columns with valid actions.",
+	"primary-key":     "The columns of the primary key exist and the identity columns are in the primary key.",
+	"index":           "The columns of the index exist and the index names are unique.",
+	"table":           "The table names are unique across the schemas and files.",
+	"domain":          "The domain of the column is defined.",
+	"mixin":           "The mixins are unique and the mixins used by the tables are defined.",
+	RuleImport:        "The object of the database can be described by the definition.",
+	RuleSnakeCase:     "The names of the tables, columns, constraints and indexes are in snake_case.",
+	RulePKRequired:    "Every table has a primary key.",
+	RulePKName:        "The single column primary key is named <table>_id.",
+	RuleFKType:        "The foreign key column has the type of the referenced column.",
+	RuleFKTarget:      "The foreign key references the primary key or a unique key.",
+	RuleFKCycle:       "The foreign keys between the tables have no cycle.",
+	RuleNameLength:    "The names are not longer than the limit of the target dialects.",
+	RuleReservedWord:  "The names are not the reserved words of the target dialects.",
+	RuleDescription:   "The tables and columns have the description.",
+	CompatAdditive:    "The change adds a new object, e.g. a nullable column.",
+	CompatWidening:    "The change relaxes the definition, e.g. a longer string or nullable.",
+	CompatNarrowing:   "The change restricts the definition, e.g. a shorter string or not null.",
+	CompatRename:      "The dropped and added objects of the same definition, probably renamed.",
+	CompatDestructive: "The change drops a table or column.",
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// sarifURI returns the uri of the file relative to the current directory
+// (the source root), or the absolute file uri if it is outside.
+func sarifURI(file string) (string, string) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return filepath.ToSlash(file), ""
+	}
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, abs); err == nil && !isOutside(rel) {
+			return filepath.ToSlash(rel), "%SRCROOT%"
+		}
+	}
+	path := filepath.ToSlash(abs)
+	if !strings.HasPrefix(path, "/") {
+		// the drive of windows, e.g. file:///C:/data/schema.yml
+		path = "/" + path
+	}
+	return "file://" + path, ""
+}
+
+// writeSarif writes the findings in SARIF 2.1.0, the rules are the rules of
+// the findings and the locations are the positions in the definition files.
+func writeSarif(w io.Writer, findings []Finding) error {
+	ids := lo.Uniq(lo.Map(findings, func(f Finding, _ int) string { return f.Rule }))
+	sort.Strings(ids)
+	rules := lo.Map(ids, func(id string, _ int) sarifRule {
+		rule := sarifRule{ID: id}
+		if desc, found := ruleDescriptions[id]; found {
+			rule.ShortDescription = &sarifMessage{Text: desc}
+		}
+		return rule
+	})
+
+	results := make([]sarifResult, 0, len(findings))
+	for _, f := range findings {
+		message := f.Message
+		if subject := strings.Join(lo.Compact([]string{f.Schema, f.Table, f.Column}), "."); subject != "" {
+			message = subject + ": " + message
+		}
+		result := sarifResult{
+			RuleID:    f.Rule,
+			RuleIndex: lo.IndexOf(ids, f.Rule),
+			Level:     lo.Ternary(f.Severity == SeverityError, "error", "warning"),
+			Message:   sarifMessage{Text: message},
+		}
+		if f.Pos.File != "" {
+			location := sarifPhysicalLocation{}
+			location.ArtifactLocation.URI, location.ArtifactLocation.URIBaseID = sarifURI(f.Pos.File)
+			if f.Pos.Line > 0 {
+				location.Region = &sarifRegion{StartLine: f.Pos.Line, StartColumn: f.Pos.Col}
+			}
+			result.Locations = []sarifLocation{{PhysicalLocation: location}}
+		}
+		results = append(results, result)
+	}
+
+	log := sarifLog{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{{Tool: sarifTool{Driver: sarifDriver{Name: "dst", Rules: rules}}, Results: results}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return tracerr.Wrap(enc.Encode(log))
+}
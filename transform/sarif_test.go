@@ -0,0 +1,133 @@
+package transform
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWriteSarif(t *testing.T) {
+	findings := []Finding{
+		{Rule: RuleSnakeCase, Severity: SeverityWarning, Schema: "app", Table: "Doc", Message: "table name 'Doc' is not in snake_case", Pos: Position{File: "schema/app.yml", Line: 4, Col: 9}},
+		// the directory name starting with .. is in the current directory
+		{Rule: "column-type", Severity: SeverityError, Schema: "app", Table: "doc", Column: "ref", Message: "missing data type", Pos: Position{File: "..schema/app.yml", Line: 7}},
+		{Rule: RuleFKCycle, Severity: SeverityWarning, Message: "tables a and b reference each other", Pos: Position{File: "/outside/schema.yml"}},
+		{Rule: RuleImport, Severity: SeverityWarning, Message: "check skipped"},
+	}
+	var buf bytes.Buffer
+	if err := WriteFindings(&buf, findings, "sarif"); err != nil {
+		t.Fatal(err)
+	}
+	want := `{
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "version": "2.1.0",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "dst",
+          "rules": [
+            {
+              "id": "column-type",
+              "shortDescription": {
+                "text": "The column has a valid data type."
+              }
+            },
+            {
+              "id": "fk-cycle",
+              "shortDescription": {
+                "text": "The foreign keys between the tables have no cycle."
+              }
+            },
+            {
+              "id": "import",
+              "shortDescription": {
+                "text": "The object of the database can be described by the definition."
+              }
+            },
+            {
+              "id": "snake-case",
+              "shortDescription": {
+                "text": "The names of the tables, columns, constraints and indexes are in snake_case."
+              }
+            }
+          ]
+        }
+      },
+      "results": [
+        {
+          "ruleId": "snake-case",
+          "ruleIndex": 3,
+          "level": "warning",
+          "message": {
+            "text": "app.Doc: table name 'Doc' is not in snake_case"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "schema/app.yml",
+                  "uriBaseId": "%SRCROOT%"
+                },
+                "region": {
+                  "startLine": 4,
+                  "startColumn": 9
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "column-type",
+          "ruleIndex": 0,
+          "level": "error",
+          "message": {
+            "text": "app.doc.ref: missing data type"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "..schema/app.yml",
+                  "uriBaseId": "%SRCROOT%"
+                },
+                "region": {
+                  "startLine": 7
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "fk-cycle",
+          "ruleIndex": 1,
+          "level": "warning",
+          "message": {
+            "text": "tables a and b reference each other"
+          },
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {
+                  "uri": "file:///outside/schema.yml"
+                }
+              }
+            }
+          ]
+        },
+        {
+          "ruleId": "import",
+          "ruleIndex": 2,
+          "level": "warning",
+          "message": {
+            "text": "check skipped"
+          }
+        }
+      ]
+    }
+  ]
+}
+`
+	if got := buf.String(); got != want {
+		t.Errorf("sarif =\n%s\nwant\n%s", got, want)
+	}
+}
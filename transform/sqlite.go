@@ -0,0 +1,439 @@
+package transform
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+	_ "modernc.org/sqlite"
+)
+
+func init() {
+	Register(Format{
+		Name:    "sqlite",
+		Aliases: []string{"sqlite3"},
+		Usage:   "SQLite DDL script",
+		Writer:  WriterFunc(func(data *DataDef, out string, opts Options) error { return WriteSqlite(data, out, opts.Types) }),
+	})
+}
+
+// sqliteReservedWords are the key words of SQLite which must be quoted when
+// used as identifier.
+var sqliteReservedWords = []string{
+	"add", "all", "alter", "and", "as", "autoincrement", "between", "case", "check", "collate", "commit",
+	"constraint", "create", "default", "deferrable", "delete", "distinct", "drop", "else", "escape", "except",
+	"exists", "foreign", "from", "group", "having", "if", "in", "index", "insert", "intersect", "into", "is",
+	"isnull", "join", "limit", "not", "notnull", "null", "on", "or", "order", "primary", "references",
+	"select", "set", "table", "then", "to", "transaction", "union", "unique", "update", "using", "values",
+	"when", "where",
+}
+
+// sqliteType returns the SQLite type affinity of the column data type, see
+// https://www.sqlite.org/datatype3.html#determination_of_column_affinity
+func sqliteType(dataType string) string {
+	t := strings.ToUpper(dataType)
+	switch {
+	case strings.Contains(t, "INT"):
+		return "INTEGER"
+	case strings.Contains(t, "CHAR"), strings.Contains(t, "CLOB"), strings.Contains(t, "TEXT"):
+		return "TEXT"
+	case strings.Contains(t, "BLOB"), strings.Contains(t, "BINARY"), t == "":
+		return "BLOB"
+	case strings.Contains(t, "REAL"), strings.Contains(t, "FLOA"), strings.Contains(t, "DOUB"):
+		return "REAL"
+	}
+	return "NUMERIC"
+}
+
+// sqliteIdent returns the quoted identifier of SQLite.
+func sqliteIdent(name string) string {
+	return quoteIdent(name, `"`, sqliteReservedWords)
+}
+
+// sqliteColumn returns the column definition of CREATE TABLE and ADD COLUMN,
+// autoInc is true if the column is the auto increment primary key.
+func sqliteColumn(column Column, autoInc bool, types TypeMappings) string {
+	line := fmt.Sprintf("%s %s", sqliteIdent(column.Name), MapType(column.Type(), DialectSqlite, types))
+	if autoInc {
+		line += " PRIMARY KEY AUTOINCREMENT"
+	}
+	if isYes(column.NotNull) {
+		line += " NOT NULL"
+	}
+	if column.Value != "" && !isYes(column.Identity) {
+		def := sqlDefault(column.Value)
+		if funcCallRegexp.MatchString(def) {
+			// expression must be in parentheses
+			def = "(" + def + ")"
+		}
+		line += " DEFAULT " + def
+	}
+	if isYes(column.Unique) {
+		line += " UNIQUE"
+	}
+	return line
+}
+
+// sqliteCreateTable returns the CREATE TABLE statement of the table with the
+// foreign keys, the indexes are not included.
+func sqliteCreateTable(data *DataDef, table Table, types TypeMappings) string {
+	var sb strings.Builder
+	columns := tableColumns(data, table)
+	pk := table.PrimaryKeyColumns(data.Fixed)
+	// only a single integer identity primary key can be auto increment
+	autoInc := false
+	if len(pk) == 1 {
+		if c, found := lo.Find(columns, func(c Column) bool { return c.Name == pk[0] }); found {
+			autoInc = isYes(c.Identity) && MapType(c.Type(), DialectSqlite, types) == "INTEGER"
+		}
+	}
+
+	lines := lo.Map(columns, func(column Column, _ int) string {
+		return "    " + sqliteColumn(column, autoInc && column.Name == pk[0], types)
+	})
+	if len(pk) > 0 && !autoInc {
+		lines = append(lines, fmt.Sprintf("    CONSTRAINT %s PRIMARY KEY (%s)", sqliteIdent("pk_"+table.Name), quoteIdents(pk, sqliteIdent)))
+	}
+	for _, check := range tableChecks(data, table, sqliteIdent) {
+		lines = append(lines, fmt.Sprintf("    CONSTRAINT %s CHECK (%s)", sqliteIdent(check.Name), check.Expr))
+	}
+	for _, fk := range data.ForeignKeys(table) {
+		lines = append(lines, fmt.Sprintf("    CONSTRAINT %s FOREIGN KEY (%s) REFERENCES %s (%s)%s",
+			sqliteIdent(fkName(table.Name, fk)), quoteIdents(fk.Columns, sqliteIdent), sqliteIdent(fk.RefTable),
+			quoteIdents(fk.RefColumns, sqliteIdent), fkActionClause(fk)))
+	}
+
+	if comment := lo.Ternary(table.Desc != "", table.Desc, table.Title); comment != "" {
+		sb.WriteString(fmt.Sprintf("-- %s\n", strings.ReplaceAll(comment, "\n", " ")))
+	}
+	sb.WriteString(fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (\n%s\n);\n", sqliteIdent(table.Name), strings.Join(lines, ",\n")))
+	return sb.String()
+}
+
+// sqliteCreateIndex returns the CREATE INDEX statement of the table index,
+// the included columns are not supported by SQLite.
+func sqliteCreateIndex(table string, ix Index) string {
+	sql := fmt.Sprintf("CREATE %sINDEX IF NOT EXISTS %s ON %s (%s)",
+		lo.Ternary(ix.Unique, "UNIQUE ", ""), sqliteIdent(indexName(table, ix)), sqliteIdent(table), indexColumns(ix, sqliteIdent))
+	if ix.Where != "" {
+		sql += " WHERE " + ix.Where
+	}
+	return sql + ";\n"
+}
+
+// WriteSqlite writes the SQLite DDL script of the definition. SQLite cannot
+// add the constraint to an existing table, the foreign keys are defined in
+// CREATE TABLE and the tables are created in the order of the dependency.
+// The schema names are ignored. The logical types are mapped by the type
+// mappings, nil for the built-in ones.
+func WriteSqlite(data *DataDef, out string, types TypeMappings) error {
+	var sb strings.Builder
+
+	tables := SortTables(data)
+
+	sb.WriteString("-- +goose Up\n")
+	for _, ref := range tables {
+		table := ref.Table
+		sb.WriteString("\n" + sqliteCreateTable(data, table, types))
+
+		// indexes
+		for _, column := range tableColumns(data, table) {
+			if isYes(column.Index) && !isYes(column.Unique) {
+				sb.WriteString(sqliteCreateIndex(table.Name, Index{Columns: []string{column.Name}}))
+			}
+		}
+		for _, ix := range table.Indexes {
+			sb.WriteString(sqliteCreateIndex(table.Name, ix))
+		}
+	}
+
+	sb.WriteString("\n-- +goose Down\n")
+	for i := len(tables) - 1; i >= 0; i-- {
+		sb.WriteString(fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", sqliteIdent(tables[i].Table.Name)))
+	}
+
+	return writeText(out, sb.String())
+}
+
+// sqliteMigrator writes the SQLite statements of the changes. SQLite cannot
+// alter the columns and constraints of an existing table, the statements are
+// replaced by the comments to rebuild the table. The foreign keys of the added
+// tables are created with the tables.
+type sqliteMigrator struct {
+	added map[string]bool
+	types TypeMappings
+}
+
+func newSqliteMigrator(_ *DataDef, changes []Change, types TypeMappings) migrator {
+	added := make(map[string]bool)
+	for _, c := range changes {
+		if c.Object == ObjectTable && c.Kind == ChangeAdd {
+			added[c.Table] = true
+		}
+	}
+	return sqliteMigrator{added: added, types: types}
+}
+
+func (m sqliteMigrator) createTable(c Change) string {
+	return sqliteCreateTable(&DataDef{}, c.New.Table, m.types)
+}
+
+func (m sqliteMigrator) dropTable(c Change) string {
+	return fmt.Sprintf("DROP TABLE IF EXISTS %s;\n", sqliteIdent(c.Table))
+}
+
+// SQLite cannot add a unique column, the unique index is created by addUnique
+func (m sqliteMigrator) addColumn(c Change) string {
+	column := c.New.Column
+	column.Unique = ""
+	return fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;\n", sqliteIdent(c.Table), sqliteColumn(column, false, m.types))
+}
+
+func (m sqliteMigrator) alterColumn(c Change) string {
+	// the type affinity may be the same, e.g. string(50) and string(100)
+	fields := lo.Filter(c.Fields, func(field string, _ int) bool {
+		return field != FieldType || MapType(c.Old.Column.Type(), DialectSqlite, m.types) != MapType(c.New.Column.Type(), DialectSqlite, m.types)
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("-- SQLite cannot alter the column %s.%s (%s), the table must be rebuilt\n", c.Table, c.Name, strings.Join(fields, ", "))
+}
+
+func (m sqliteMigrator) dropColumn(c Change) string {
+	return fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;\n", sqliteIdent(c.Table), sqliteIdent(c.Name))
+}
+
+func (m sqliteMigrator) createIndex(c Change) string {
+	return sqliteCreateIndex(c.Table, c.New.Index)
+}
+
+func (m sqliteMigrator) dropIndex(c Change) string {
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s;\n", sqliteIdent(c.Name))
+}
+
+func (m sqliteMigrator) addForeignKey(c Change) string {
+	if m.added[c.Table] {
+		return ""
+	}
+	return fmt.Sprintf("-- SQLite cannot add the foreign key %s to the table %s, the table must be rebuilt\n", c.Name, c.Table)
+}
+
+func (m sqliteMigrator) dropForeignKey(c Change) string {
+	return fmt.Sprintf("-- SQLite cannot drop the foreign key %s of the table %s, the table must be rebuilt\n", c.Name, c.Table)
+}
+
+func (m sqliteMigrator) addCheck(c Change) string {
+	return fmt.Sprintf("-- SQLite cannot add the check %s to the table %s, the table must be rebuilt\n", c.Name, c.Table)
+}
+
+func (m sqliteMigrator) dropCheck(c Change) string {
+	return fmt.Sprintf("-- SQLite cannot drop the check %s of the table %s, the table must be rebuilt\n", c.Name, c.Table)
+}
+
+// the unique constraint of an existing table is the unique index of SQLite
+func (m sqliteMigrator) addUnique(c Change) string {
+	return fmt.Sprintf("CREATE UNIQUE INDEX IF NOT EXISTS %s ON %s (%s);\n", sqliteIdent(c.Name), sqliteIdent(c.Table), sqliteIdent(c.New.Column.Name))
+}
+
+// the unique index is dropped, the unique constraint of CREATE TABLE cannot be
+func (m sqliteMigrator) dropUnique(c Change) string {
+	return fmt.Sprintf("DROP INDEX IF EXISTS %s;\n", sqliteIdent(c.Name)) +
+		fmt.Sprintf("-- SQLite cannot drop the unique constraint of %s.%s created with the table, the table must be rebuilt\n", c.Table, c.Old.Column.Name)
+}
+
+var (
+	sqliteWhereRegexp = regexp.MustCompile(`(?is)\bWHERE\b(.*?);?\s*$`)
+	sqliteCheckRegexp = regexp.MustCompile(`(?i)\bCHECK\s*\(`)
+)
+
+// ImportSqlite reads the definition of the SQLite database file, which are
+// the tables (matching the table pattern) with the columns, primary keys,
+// foreign keys and indexes in the schema "main". The single integer primary
+// key (the rowid) is the identity column. The objects cannot be imported are
+// reported as the warnings, e.g. the expression indexes and checks.
+func ImportSqlite(file string, tablePattern string) (*DataDef, []Finding, error) {
+	// the driver creates the file if not exists
+	if _, err := os.Stat(file); err != nil {
+		return nil, nil, tracerr.Wrap(err)
+	}
+	db, err := sql.Open("sqlite", "file:"+file+"?mode=ro")
+	if err != nil {
+		return nil, nil, tracerr.Wrap(err)
+	}
+	defer db.Close()
+
+	schema := Schema{Name: "main", Tables: make([]Table, 0)}
+	findings := make([]Finding, 0)
+	err = queryRows(db, `SELECT name, sql FROM sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite\_%' ESCAPE '\' ORDER BY rowid`, nil, func(rows *sql.Rows) error {
+		var table Table
+		var ddl string
+		if err := rows.Scan(&table.Name, &ddl); err != nil {
+			return err
+		}
+		if !matchPattern(tablePattern, table.Name) {
+			return nil
+		}
+		if sqliteCheckRegexp.MatchString(ddl) {
+			findings = append(findings, importWarning(schema.Name, table.Name, "check constraints are not imported"))
+		}
+		schema.Tables = append(schema.Tables, table)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for i := range schema.Tables {
+		table := &schema.Tables[i]
+		if err := sqliteImportColumns(db, table); err != nil {
+			return nil, nil, err
+		}
+		if err := sqliteImportForeignKeys(db, table); err != nil {
+			return nil, nil, err
+		}
+		warnings, err := sqliteImportIndexes(db, table)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, warning := range warnings {
+			findings = append(findings, importWarning(schema.Name, table.Name, warning))
+		}
+	}
+	return &DataDef{Schemas: []Schema{schema}}, findings, nil
+}
+
+// sqliteImportColumns reads the columns and the primary key of the table.
+func sqliteImportColumns(db *sql.DB, table *Table) error {
+	pk := make(map[int]string)
+	err := queryRows(db, `SELECT name, type, "notnull", dflt_value, pk FROM pragma_table_info(?) ORDER BY cid`, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var column Column
+		var notNull bool
+		var value sql.NullString
+		var pos int
+		if err := rows.Scan(&column.Name, &column.DataType, &notNull, &value, &pos); err != nil {
+			return err
+		}
+		column.DataType = importType(column.DataType)
+		column.NotNull = lo.Ternary(notNull, "Y", "")
+		column.Value = importDefault(value.String)
+		if pos > 0 {
+			pk[pos] = column.Name
+		}
+		table.Columns = append(table.Columns, column)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(pk) == 1 {
+		if column := findColumn(table, pk[1]); column != nil && strings.EqualFold(column.DataType, "INTEGER") {
+			column.Identity = "Y"
+		}
+	}
+	names := make([]string, 0, len(pk))
+	for i := 1; i <= len(pk); i++ {
+		names = append(names, pk[i])
+	}
+	importPrimaryKey(table, names)
+	return nil
+}
+
+// sqliteImportForeignKeys reads the foreign keys of the table.
+func sqliteImportForeignKeys(db *sql.DB, table *Table) error {
+	fks := make([]ForeignKey, 0)
+	last := -1
+	err := queryRows(db, `SELECT id, "table", "from", "to", on_update, on_delete FROM pragma_foreign_key_list(?) ORDER BY id, seq`, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var id int
+		var refTable, column, onUpdate, onDelete string
+		var refColumn sql.NullString
+		if err := rows.Scan(&id, &refTable, &column, &refColumn, &onUpdate, &onDelete); err != nil {
+			return err
+		}
+		if id != last {
+			fks = append(fks, ForeignKey{RefTable: refTable, OnDelete: importAction(onDelete), OnUpdate: importAction(onUpdate)})
+			last = id
+		}
+		fk := &fks[len(fks)-1]
+		fk.Columns = append(fk.Columns, column)
+		// the primary key of the referenced table is referenced if no column
+		if refColumn.Valid {
+			fk.RefColumns = append(fk.RefColumns, refColumn.String)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// the foreign keys are listed in the reverse order of the definition
+	for i := len(fks) - 1; i >= 0; i-- {
+		importForeignKey(table, fks[i])
+	}
+	return nil
+}
+
+// sqliteImportIndexes reads the indexes and the unique constraints of the
+// table, it returns the warnings of the indexes not imported.
+func sqliteImportIndexes(db *sql.DB, table *Table) ([]string, error) {
+	type indexInfo struct {
+		index  Index
+		origin string
+		ddl    string
+	}
+	infos := make([]indexInfo, 0)
+	err := queryRows(db, `SELECT il.name, il."unique", il.origin, COALESCE(m.sql, '') FROM pragma_index_list(?) il
+		LEFT JOIN sqlite_master m ON m.type = 'index' AND m.name = il.name ORDER BY m.rowid`, []interface{}{table.Name}, func(rows *sql.Rows) error {
+		var info indexInfo
+		if err := rows.Scan(&info.index.Name, &info.index.Unique, &info.origin, &info.ddl); err != nil {
+			return err
+		}
+		infos = append(infos, info)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	warnings := make([]string, 0)
+	for _, info := range infos {
+		if info.origin == "pk" {
+			continue
+		}
+		ix := info.index
+		expression := false
+		err := queryRows(db, `SELECT name, "desc" FROM pragma_index_xinfo(?) WHERE key = 1 ORDER BY seqno`, []interface{}{ix.Name}, func(rows *sql.Rows) error {
+			var name sql.NullString
+			var desc bool
+			if err := rows.Scan(&name, &desc); err != nil {
+				return err
+			}
+			expression = expression || !name.Valid
+			ix.Columns = append(ix.Columns, name.String+lo.Ternary(desc, " DESC", ""))
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if expression {
+			warnings = append(warnings, fmt.Sprintf("index '%s' on the expression is not imported", ix.Name))
+			continue
+		}
+
+		if info.origin == "u" {
+			// the name of the unique constraint is generated, e.g. sqlite_autoindex_doc_1
+			importUnique(table, "", ix.Columns)
+			continue
+		}
+		if m := sqliteWhereRegexp.FindStringSubmatch(info.ddl); m != nil {
+			ix.Where = strings.TrimSpace(m[1])
+		}
+		importIndex(table, ix)
+	}
+	return warnings, nil
+}
@@ -0,0 +1,193 @@
+package transform
+
+import (
+	"database/sql"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// sqliteTestDB creates the SQLite database of the script in a temporary
+// directory and returns the file.
+func sqliteTestDB(t *testing.T, script string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "app.db")
+	db, err := sql.Open("sqlite", file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if _, err := db.Exec(script); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestWriteSqlite(t *testing.T) {
+	// the types are mapped to the affinities, the foreign keys are inlined and
+	// the referenced tables are created first
+	data := readTestYml(t, `schemas:
+  - name: app
+    tables:
+      - name: doc_tag
+        primary_key: [doc_id, tag_id]
+        columns:
+          - { na: doc_id, ty: INT, nu: Y, fk: doc.doc_id }
+          - { na: tag_id, ty: INT, nu: Y, fk: tag.tag_id }
+      - name: doc
+        columns:
+          - { na: doc_id, ty: BIGINT, id: Y, nu: Y }
+          - { na: title, ty: NVARCHAR(100), nu: Y, in: Y }
+          - { na: price, ty: DECIMAL(10,2), va: "0" }
+          - { na: rate, ty: DOUBLE }
+          - { na: data, ty: BLOB }
+          - { na: created, ty: DATETIME, va: CURRENT_TIMESTAMP }
+          - { na: parent_id, ty: BIGINT, fk: doc.doc_id }
+      - name: tag
+        columns:
+          - { na: tag_id, ty: INT, id: Y, nu: Y }
+          - { na: name, ty: VARCHAR(50), nu: Y, un: Y }
+`)
+	got := readTestOutput(t, "sqlite.sql", func(out string) error { return WriteSqlite(data, out, nil) })
+	want := `-- +goose Up
+
+CREATE TABLE IF NOT EXISTS doc (
+    doc_id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    title TEXT NOT NULL,
+    price NUMERIC DEFAULT 0,
+    rate REAL,
+    data BLOB,
+    created NUMERIC DEFAULT CURRENT_TIMESTAMP,
+    parent_id INTEGER,
+    CONSTRAINT fk_doc_parent_id FOREIGN KEY (parent_id) REFERENCES doc (doc_id)
+);
+CREATE INDEX IF NOT EXISTS idx_doc_title ON doc (title);
+
+CREATE TABLE IF NOT EXISTS tag (
+    tag_id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS doc_tag (
+    doc_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+    CONSTRAINT pk_doc_tag PRIMARY KEY (doc_id, tag_id),
+    CONSTRAINT fk_doc_tag_doc_id FOREIGN KEY (doc_id) REFERENCES doc (doc_id),
+    CONSTRAINT fk_doc_tag_tag_id FOREIGN KEY (tag_id) REFERENCES tag (tag_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS doc_tag;
+DROP TABLE IF EXISTS tag;
+DROP TABLE IF EXISTS doc;
+`
+	if got != want {
+		t.Errorf("script =\n%s\nwant\n%s", got, want)
+	}
+	// the script is run by SQLite
+	up, _, _ := strings.Cut(got, "-- +goose Down")
+	sqliteTestDB(t, up)
+}
+
+func TestImportSqlite(t *testing.T) {
+	file := sqliteTestDB(t, `
+CREATE TABLE doc (
+  doc_id INTEGER PRIMARY KEY AUTOINCREMENT,
+  code varchar(10) NOT NULL UNIQUE,
+  title TEXT DEFAULT 'untitled',
+  ver INT NOT NULL DEFAULT 0,
+  kind CHAR(1) CHECK (kind IN ('A', 'B'))
+);
+CREATE TABLE tag (
+  tag_id INTEGER PRIMARY KEY,
+  name VARCHAR(20)
+);
+CREATE TABLE doc_tag (
+  doc_id INTEGER NOT NULL REFERENCES doc (doc_id),
+  tag_id INTEGER NOT NULL,
+  created TEXT,
+  PRIMARY KEY (doc_id, tag_id),
+  CONSTRAINT fk_doc_tag_tag FOREIGN KEY (tag_id) REFERENCES tag (tag_id) ON DELETE CASCADE
+);
+CREATE INDEX ix_doc_tag_created ON doc_tag (created DESC, tag_id);
+CREATE UNIQUE INDEX ux_tag_name ON tag (name) WHERE name IS NOT NULL;
+CREATE INDEX ix_tag_lower ON tag (lower(name));
+`)
+	data, findings, err := ImportSqlite(file, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertDataDef(t, data, `schemas:
+  - name: main
+    tables:
+      - name: doc
+        columns:
+          - {na: doc_id, ty: INTEGER, id: Y, nu: Y}
+          - {na: code, ty: VARCHAR(10), nu: Y, un: Y}
+          - {na: title, ty: TEXT, va: untitled}
+          - {na: ver, ty: INT, nu: Y, va: "0"}
+          - {na: kind, ty: CHAR(1)}
+      - name: tag
+        columns:
+          - {na: tag_id, ty: INTEGER, id: Y, nu: Y}
+          - {na: name, ty: VARCHAR(20)}
+        indexes:
+          - {name: ux_tag_name, columns: [name], unique: true, where: name IS NOT NULL}
+      - name: doc_tag
+        primary_key: [doc_id, tag_id]
+        columns:
+          - {na: doc_id, ty: INTEGER, nu: Y, fk: doc.doc_id}
+          - {na: tag_id, ty: INTEGER, nu: Y}
+          - {na: created, ty: TEXT}
+        foreign_keys:
+          - {columns: [tag_id], ref_table: tag, ref_columns: [tag_id], on_delete: CASCADE}
+        indexes:
+          - {name: ix_doc_tag_created, columns: [created DESC, tag_id]}
+`)
+	got := make([]string, 0, len(findings))
+	for _, f := range findings {
+		got = append(got, f.Table+": "+f.Message)
+	}
+	want := []string{
+		"doc: check constraints are not imported",
+		"tag: index 'ix_tag_lower' on the expression is not imported",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("warnings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestSqliteRoundTrip(t *testing.T) {
+	// the types are the SQLite types, the others are mapped to the affinities
+	def := `schemas:
+  - name: main
+    tables:
+      - name: doc
+        columns:
+          - {na: doc_id, ty: INTEGER, id: Y, nu: Y}
+          - {na: code, ty: TEXT, nu: Y, un: Y}
+          - {na: ver, ty: INTEGER, nu: Y, va: "0"}
+          - {na: price, ty: REAL}
+        indexes:
+          - {name: ix_doc_ver, columns: [ver DESC, code]}
+      - name: doc_tag
+        primary_key: [doc_id, tag]
+        columns:
+          - {na: doc_id, ty: INTEGER, nu: Y, fk: doc.doc_id}
+          - {na: tag, ty: TEXT, nu: Y}
+        foreign_keys:
+          - {columns: [tag], ref_table: doc, ref_columns: [code], on_delete: CASCADE}
+`
+	script := readTestOutput(t, "sqlite.sql", func(out string) error {
+		return WriteSqlite(readTestYml(t, def), out, nil)
+	})
+	up, _, _ := strings.Cut(script, "-- +goose Down")
+	data, findings, err := ImportSqlite(sqliteTestDB(t, up), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) > 0 {
+		t.Errorf("unexpected findings %v", findings)
+	}
+	assertDataDef(t, data, def)
+}
@@ -0,0 +1,1077 @@
+package transform
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+)
+
+// SQLImportDialects are the dialects of the DDL scripts read by ImportSQL.
+var SQLImportDialects = []string{DialectMariaDB, DialectMSSQL, DialectPostgres, DialectSqlite}
+
+// the kinds of the SQL tokens
+const (
+	sqlWord   = iota // keyword or identifier
+	sqlIdent         // quoted identifier
+	sqlString        // string literal
+	sqlNumber        // number
+	sqlPunct         // punctuation and operator
+)
+
+// sqlToken is a token of the SQL script, the text of the quoted identifier
+// and string literal is unquoted.
+type sqlToken struct {
+	kind  int
+	text  string
+	start int  // offset of the token in the script
+	end   int  // offset after the token
+	line  int  // line of the token
+	first bool // true if it is the first token of the line
+}
+
+var (
+	gooseDownRegexp = regexp.MustCompile(`(?i)^--\s*\+goose\s+down\b`)
+	dollarTagRegexp = regexp.MustCompile(`^\$[A-Za-z_]*\$`)
+)
+
+// serialTypes are the PostgreSQL serial types and the integer types
+var serialTypes = map[string]string{
+	"SMALLSERIAL": "SMALLINT", "SERIAL2": "SMALLINT",
+	"SERIAL": "INTEGER", "SERIAL4": "INTEGER",
+	"BIGSERIAL": "BIGINT", "SERIAL8": "BIGINT",
+}
+
+// lexSQL returns the tokens of the SQL script without the comments, the
+// script after the goose down annotation (-- +goose Down) is skipped.
+func lexSQL(src string, dialect string) []sqlToken {
+	isWordChar := func(c byte) bool {
+		return c == '_' || c == '$' || c == '@' || c == '#' || c >= 0x80 ||
+			c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+	}
+	// scan returns the unquoted text and the offset after the closing quote
+	scan := func(i int, close byte, backslash bool) (string, int) {
+		var sb strings.Builder
+		for j := i + 1; j < len(src); j++ {
+			switch {
+			case backslash && src[j] == '\\' && j+1 < len(src):
+				j++
+				sb.WriteByte(src[j])
+			case src[j] == close && j+1 < len(src) && src[j+1] == close:
+				j++
+				sb.WriteByte(close)
+			case src[j] == close:
+				return sb.String(), j + 1
+			default:
+				sb.WriteByte(src[j])
+			}
+		}
+		return sb.String(), len(src)
+	}
+
+	tokens := make([]sqlToken, 0)
+	line, first := 1, true
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line, first = line+1, true
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			i++
+			continue
+		case strings.HasPrefix(src[i:], "--") || c == '#' && dialect == DialectMariaDB:
+			end := strings.IndexByte(src[i:], '\n')
+			if end < 0 {
+				end = len(src) - i
+			}
+			if gooseDownRegexp.MatchString(src[i : i+end]) {
+				return tokens
+			}
+			i += end
+			continue
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			end = lo.Ternary(end < 0, len(src), i+2+end+2)
+			line += strings.Count(src[i:end], "\n")
+			i = end
+			continue
+		}
+
+		tok := sqlToken{start: i, line: line, first: first}
+		first = false
+		switch {
+		case c == '\'':
+			tok.kind = sqlString
+			tok.text, i = scan(i, '\'', dialect == DialectMariaDB)
+		case strings.ContainsRune("NnEe", rune(c)) && i+1 < len(src) && src[i+1] == '\'':
+			// N'unicode' of mssql and E'escaped' of postgres
+			tok.kind = sqlString
+			tok.text, i = scan(i+1, '\'', c == 'E' || c == 'e' || dialect == DialectMariaDB)
+		case c == '"':
+			tok.kind = sqlIdent
+			tok.text, i = scan(i, '"', false)
+		case c == '`' && (dialect == DialectMariaDB || dialect == DialectSqlite):
+			tok.kind = sqlIdent
+			tok.text, i = scan(i, '`', false)
+		case c == '[' && (dialect == DialectMSSQL || dialect == DialectSqlite):
+			tok.kind = sqlIdent
+			tok.text, i = scan(i, ']', false)
+		case c == '$' && dialect == DialectPostgres && dollarTagRegexp.MatchString(src[i:]):
+			tag := dollarTagRegexp.FindString(src[i:])
+			end := strings.Index(src[i+len(tag):], tag)
+			end = lo.Ternary(end < 0, len(src), i+len(tag)+end+len(tag))
+			tok.kind = sqlString
+			tok.text = strings.TrimSuffix(src[i+len(tag):end], tag)
+			i = end
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(src) && src[i+1] >= '0' && src[i+1] <= '9':
+			tok.kind = sqlNumber
+			for i++; i < len(src) && (src[i] >= '0' && src[i] <= '9' || src[i] == '.'); i++ {
+			}
+			tok.text = src[tok.start:i]
+		case isWordChar(c):
+			tok.kind = sqlWord
+			for i++; i < len(src) && isWordChar(src[i]); i++ {
+			}
+			tok.text = src[tok.start:i]
+		case strings.HasPrefix(src[i:], "::"):
+			tok.kind, tok.text = sqlPunct, "::"
+			i += 2
+		default:
+			tok.kind, tok.text = sqlPunct, string(c)
+			i++
+		}
+		tok.end = i
+		line += strings.Count(src[tok.start:tok.end], "\n")
+		tokens = append(tokens, tok)
+	}
+	return tokens
+}
+
+// splitStatements splits the tokens into the statements by the semicolon,
+// and the GO line of mssql.
+func splitStatements(tokens []sqlToken, dialect string) [][]sqlToken {
+	statements := make([][]sqlToken, 0)
+	start := 0
+	for i, tok := range tokens {
+		separator := tok.kind == sqlPunct && tok.text == ";" ||
+			dialect == DialectMSSQL && tok.first && tok.kind == sqlWord && strings.EqualFold(tok.text, "GO")
+		if separator {
+			if i > start {
+				statements = append(statements, tokens[start:i])
+			}
+			start = i + 1
+		}
+	}
+	if start < len(tokens) {
+		statements = append(statements, tokens[start:])
+	}
+	return statements
+}
+
+// sqlParser parses the tokens of a statement.
+type sqlParser struct {
+	src    string
+	tokens []sqlToken
+	pos    int
+}
+
+// done returns true if all tokens are parsed.
+func (p *sqlParser) done() bool {
+	return p.pos >= len(p.tokens)
+}
+
+// peek returns the current token, the kind is -1 if all tokens are parsed.
+func (p *sqlParser) peek() sqlToken {
+	if p.done() {
+		return sqlToken{kind: -1}
+	}
+	return p.tokens[p.pos]
+}
+
+// next returns the current token and moves to the next one.
+func (p *sqlParser) next() sqlToken {
+	tok := p.peek()
+	if !p.done() {
+		p.pos++
+	}
+	return tok
+}
+
+// isWord returns true if the current token is any of the keywords.
+func (p *sqlParser) isWord(words ...string) bool {
+	tok := p.peek()
+	return tok.kind == sqlWord && lo.ContainsBy(words, func(w string) bool { return strings.EqualFold(w, tok.text) })
+}
+
+// acceptWord moves to the next token if the current one is any of the keywords.
+func (p *sqlParser) acceptWord(words ...string) bool {
+	if p.isWord(words...) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// isWords returns true if the tokens from the current one are the keywords
+// in order.
+func (p *sqlParser) isWords(words ...string) bool {
+	start := p.pos
+	defer func() { p.pos = start }()
+	return p.acceptWords(words...)
+}
+
+// acceptWords moves after the keywords if the tokens are the keywords in order.
+func (p *sqlParser) acceptWords(words ...string) bool {
+	start := p.pos
+	for _, word := range words {
+		if !p.acceptWord(word) {
+			p.pos = start
+			return false
+		}
+	}
+	return true
+}
+
+// isPunct returns true if the current token is the punctuation.
+func (p *sqlParser) isPunct(s string) bool {
+	tok := p.peek()
+	return tok.kind == sqlPunct && tok.text == s
+}
+
+// acceptPunct moves to the next token if the current one is the punctuation.
+func (p *sqlParser) acceptPunct(s string) bool {
+	if p.isPunct(s) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// isEnd returns true if the current token ends the element of the list.
+func (p *sqlParser) isEnd() bool {
+	return p.done() || p.isPunct(",") || p.isPunct(")")
+}
+
+// text returns the source text of the tokens from start to the current one.
+func (p *sqlParser) text(start int) string {
+	if start >= p.pos {
+		return ""
+	}
+	return p.src[p.tokens[start].start:p.tokens[p.pos-1].end]
+}
+
+// name returns the identifier.
+func (p *sqlParser) name() (string, error) {
+	tok := p.peek()
+	if tok.kind != sqlWord && tok.kind != sqlIdent {
+		return "", p.unexpected("name")
+	}
+	p.pos++
+	return tok.text, nil
+}
+
+// qualifiedName returns the names separated by dot, e.g. schema.table.
+func (p *sqlParser) qualifiedName() ([]string, error) {
+	names := make([]string, 0)
+	for {
+		name, err := p.name()
+		if err != nil {
+			return nil, err
+		}
+		names = append(names, name)
+		if !p.acceptPunct(".") {
+			return names, nil
+		}
+	}
+}
+
+// tableName returns the schema and the table name, the schema is empty if
+// not qualified.
+func (p *sqlParser) tableName() (string, string, error) {
+	names, err := p.qualifiedName()
+	if err != nil {
+		return "", "", err
+	}
+	if len(names) == 1 {
+		return "", names[0], nil
+	}
+	return names[len(names)-2], names[len(names)-1], nil
+}
+
+// skip moves after the expression until the end of the element or the stop
+// keywords, the parentheses are skipped as a whole. It returns the text.
+func (p *sqlParser) skip(stop ...string) string {
+	start, depth := p.pos, 0
+	for !p.done() {
+		if depth == 0 && (p.isEnd() || len(stop) > 0 && p.isWord(stop...)) {
+			break
+		}
+		switch {
+		case p.isPunct("("):
+			depth++
+		case p.isPunct(")"):
+			depth--
+		}
+		p.pos++
+	}
+	return p.text(start)
+}
+
+// group moves after the parentheses of the current token, the nested ones
+// are included.
+func (p *sqlParser) group() {
+	for depth := 0; !p.done(); {
+		switch tok := p.next(); {
+		case tok.kind == sqlPunct && tok.text == "(":
+			depth++
+		case tok.kind == sqlPunct && tok.text == ")":
+			depth--
+		}
+		if depth <= 0 {
+			return
+		}
+	}
+}
+
+// parens returns the text in the parentheses.
+func (p *sqlParser) parens() (string, error) {
+	if !p.acceptPunct("(") {
+		return "", p.unexpected("(")
+	}
+	text := p.skip()
+	if !p.acceptPunct(")") {
+		return "", p.unexpected(")")
+	}
+	return strings.TrimSpace(text), nil
+}
+
+// indexColumns returns the columns in the parentheses with the sort order,
+// expression is true if any column is an expression or a prefix.
+func (p *sqlParser) indexColumns() (columns []string, expression bool, err error) {
+	if !p.acceptPunct("(") {
+		return nil, false, p.unexpected("(")
+	}
+	for {
+		tok := p.peek()
+		if tok.kind == sqlWord || tok.kind == sqlIdent {
+			p.pos++
+			column := tok.text
+			if p.acceptWord("DESC") {
+				column += " DESC"
+			} else {
+				p.acceptWord("ASC")
+			}
+			p.acceptWords("NULLS", "FIRST")
+			p.acceptWords("NULLS", "LAST")
+			columns = append(columns, column)
+		}
+		if !p.isEnd() {
+			p.skip()
+			expression = true
+		}
+		if p.acceptPunct(")") {
+			return columns, expression, nil
+		}
+		if !p.acceptPunct(",") {
+			return nil, false, p.unexpected(")")
+		}
+	}
+}
+
+// columnNames returns the column names in the parentheses.
+func (p *sqlParser) columnNames() ([]string, error) {
+	columns, expression, err := p.indexColumns()
+	if err != nil {
+		return nil, err
+	}
+	if expression {
+		return nil, tracerr.New("expression is not a column name")
+	}
+	return columns, nil
+}
+
+// references returns the foreign key after REFERENCES, e.g. doc (doc_id)
+// ON DELETE CASCADE.
+func (p *sqlParser) references() (ForeignKey, error) {
+	var fk ForeignKey
+	var err error
+	if _, fk.RefTable, err = p.tableName(); err != nil {
+		return fk, err
+	}
+	if p.isPunct("(") {
+		if fk.RefColumns, err = p.columnNames(); err != nil {
+			return fk, err
+		}
+	}
+	action := func() string {
+		start := p.pos
+		if !p.acceptWords("NO", "ACTION") && !p.acceptWords("SET", "NULL") && !p.acceptWords("SET", "DEFAULT") {
+			p.acceptWord("CASCADE", "RESTRICT")
+		}
+		return importAction(strings.Join(lo.Map(p.tokens[start:p.pos], func(t sqlToken, _ int) string { return t.text }), " "))
+	}
+	for {
+		switch {
+		case p.acceptWords("ON", "DELETE"):
+			fk.OnDelete = action()
+		case p.acceptWords("ON", "UPDATE"):
+			fk.OnUpdate = action()
+		case p.acceptWord("MATCH"):
+			p.next()
+		case p.acceptWords("NOT", "FOR", "REPLICATION"), p.acceptWords("NOT", "DEFERRABLE"), p.acceptWord("DEFERRABLE"):
+		case p.acceptWord("INITIALLY"):
+			p.next()
+		default:
+			return fk, nil
+		}
+	}
+}
+
+// unexpected returns the error of the unexpected token.
+func (p *sqlParser) unexpected(expected string) error {
+	if p.done() {
+		return tracerr.Errorf("expected %s at the end of the statement", expected)
+	}
+	return tracerr.Errorf("expected %s but found '%s'", expected, p.peek().text)
+}
+
+// sqlImport is the definition read from the DDL script.
+type sqlImport struct {
+	file     string
+	dialect  string
+	schema   string // the default schema
+	data     *DataDef
+	findings []Finding
+	pos      Position // the position of the current statement
+}
+
+// warn adds the warning of the current statement.
+func (s *sqlImport) warn(table string, message string) {
+	f := importWarning("", table, message)
+	f.Pos = s.pos
+	s.findings = append(s.findings, f)
+}
+
+// findSchema returns the schema of the name, it is added if not found.
+func (s *sqlImport) findSchema(name string) *Schema {
+	name = lo.Ternary(name != "", name, s.schema)
+	for i := range s.data.Schemas {
+		if strings.EqualFold(s.data.Schemas[i].Name, name) {
+			return &s.data.Schemas[i]
+		}
+	}
+	s.data.Schemas = append(s.data.Schemas, Schema{Name: name})
+	return &s.data.Schemas[len(s.data.Schemas)-1]
+}
+
+// findTable returns the table of the name, it is searched in all schemas if
+// the schema is empty. It returns nil if not found.
+func (s *sqlImport) findTable(schema string, name string) *Table {
+	for i := range s.data.Schemas {
+		if schema != "" && !strings.EqualFold(s.data.Schemas[i].Name, schema) {
+			continue
+		}
+		tables := s.data.Schemas[i].Tables
+		for j := range tables {
+			if strings.EqualFold(tables[j].Name, name) {
+				return &tables[j]
+			}
+		}
+	}
+	return nil
+}
+
+// ImportSQL reads the definition of the DDL script of the dialect (mariadb,
+// mssql, postgres or sqlite), which are the tables of CREATE TABLE with the
+// columns, primary keys, foreign keys, unique constraints, checks and
+// indexes, the constraints of ALTER TABLE ADD, the indexes of CREATE INDEX
+// and the comments of COMMENT ON (or MS_Description of mssql). DROP is
+// ignored as the down script, and the statements cannot be imported are
+// reported as the warnings. The tables without schema are in the default
+// schema: public of postgres, dbo of mssql, main of sqlite, or the database
+// of USE (the file name by default) of mariadb.
+func ImportSQL(file string, dialect string) (*DataDef, []Finding, error) {
+	dialect = strings.ToLower(dialect)
+	if !lo.Contains(SQLImportDialects, dialect) {
+		return nil, nil, tracerr.Errorf("unsupported dialect '%s', supported dialects: %s", dialect, strings.Join(SQLImportDialects, ", "))
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, tracerr.Wrap(err)
+	}
+	src := string(content)
+
+	s := &sqlImport{file: file, dialect: dialect, data: &DataDef{Schemas: make([]Schema, 0)}, findings: make([]Finding, 0)}
+	switch dialect {
+	case DialectPostgres:
+		s.schema = "public"
+	case DialectMSSQL:
+		s.schema = "dbo"
+	case DialectSqlite:
+		s.schema = "main"
+	default:
+		s.schema = strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+	}
+	for _, statement := range splitStatements(lexSQL(src, dialect), dialect) {
+		s.pos = Position{File: file, Line: statement[0].line, Col: statement[0].start - strings.LastIndexByte(src[:statement[0].start], '\n')}
+		if err := s.statement(&sqlParser{src: src, tokens: statement}); err != nil {
+			summary := strings.Join(strings.Fields(src[statement[0].start:statement[len(statement)-1].end]), " ")
+			if len(summary) > 60 {
+				summary = summary[:57] + "..."
+			}
+			s.warn("", "statement is not imported, "+err.Error()+": "+summary)
+		}
+	}
+
+	s.data.Schemas = lo.Filter(s.data.Schemas, func(schema Schema, _ int) bool { return len(schema.Tables) > 0 })
+	return s.data, s.findings, nil
+}
+
+// statement reads the statement, it returns an error if the statement is not
+// supported or invalid.
+func (s *sqlImport) statement(p *sqlParser) error {
+	// the condition of mssql, e.g. IF OBJECT_ID(N'doc', N'U') IS NULL BEGIN CREATE TABLE ... END
+	if p.isWord("IF") {
+		for !p.done() && !p.isWord("CREATE", "ALTER", "COMMENT", "EXEC", "EXECUTE") {
+			if p.isPunct("(") {
+				p.group()
+			} else {
+				p.next()
+			}
+		}
+	}
+
+	switch {
+	case p.acceptWord("CREATE"):
+		p.acceptWords("OR", "REPLACE")
+		switch {
+		case p.acceptWord("TABLE"):
+			return s.createTable(p)
+		case p.isWord("UNIQUE", "INDEX", "CLUSTERED", "NONCLUSTERED"):
+			return s.createIndex(p)
+		case p.acceptWord("SCHEMA"):
+			p.acceptWords("IF", "NOT", "EXISTS")
+			name, err := p.name()
+			if err == nil {
+				s.findSchema(name)
+			}
+			return err
+		}
+	case p.acceptWord("ALTER"):
+		if p.acceptWord("TABLE") {
+			return s.alterTable(p)
+		}
+	case p.acceptWord("COMMENT"):
+		return s.comment(p)
+	case p.acceptWord("EXEC", "EXECUTE"):
+		return s.extendedProperty(p)
+	case p.acceptWord("USE"):
+		name, err := p.name()
+		if err == nil && s.dialect == DialectMariaDB {
+			s.schema = name
+		}
+		return err
+	case p.isWord("DROP", "SET", "BEGIN", "COMMIT", "START", "ROLLBACK", "END", "PRINT", "DELIMITER", "PRAGMA"):
+		// the drops of the down script, the session and transaction statements
+		return nil
+	}
+	return tracerr.New("unsupported statement")
+}
+
+// createTable reads CREATE TABLE, the table is added to the schema.
+func (s *sqlImport) createTable(p *sqlParser) error {
+	p.acceptWords("IF", "NOT", "EXISTS")
+	schema, name, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	if !p.acceptPunct("(") {
+		return tracerr.New("table definition not found")
+	}
+	table := Table{Name: name}
+	constraints := make([]func(*Table), 0)
+	for {
+		if err := s.tableElement(p, &table, &constraints); err != nil {
+			return err
+		}
+		if p.acceptPunct(")") {
+			break
+		}
+		if !p.acceptPunct(",") {
+			return p.unexpected(", or )")
+		}
+	}
+	// the table options, e.g. ENGINE=InnoDB COMMENT='document'
+	for !p.done() {
+		if p.acceptWord("COMMENT") {
+			p.acceptPunct("=")
+			if p.peek().kind == sqlString {
+				table.Desc = p.next().text
+			}
+			continue
+		}
+		p.next()
+	}
+	for _, add := range constraints {
+		add(&table)
+	}
+	// the single INTEGER primary key of sqlite is the rowid, e.g. id INTEGER PRIMARY KEY AUTOINCREMENT
+	if pk := table.PrimaryKeyColumns(nil); s.dialect == DialectSqlite && len(pk) == 1 {
+		if column := findColumn(&table, pk[0]); column != nil && strings.EqualFold(column.DataType, "INTEGER") {
+			column.Identity, table.PrimaryKey = "Y", nil
+		}
+	}
+
+	if s.findTable(schema, name) != nil {
+		s.warn(name, "table is defined again, the later one is ignored")
+		return nil
+	}
+	target := s.findSchema(schema)
+	target.Tables = append(target.Tables, table)
+	return nil
+}
+
+// alterTable reads ALTER TABLE ADD of the columns and constraints, DROP is
+// ignored.
+func (s *sqlImport) alterTable(p *sqlParser) error {
+	p.acceptWords("IF", "EXISTS")
+	p.acceptWord("ONLY")
+	schema, name, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	if p.isWord("DROP") {
+		// the drops of the down script
+		return nil
+	}
+	table := s.findTable(schema, name)
+	if table == nil {
+		return tracerr.Errorf("table '%s' not found", name)
+	}
+	for {
+		// WITH CHECK or WITH NOCHECK of mssql
+		if p.acceptWord("WITH") {
+			p.next()
+		}
+		if !p.acceptWord("ADD") {
+			return tracerr.New("only ADD is supported")
+		}
+		if p.acceptWord("COLUMN") || p.acceptWords("IF", "NOT", "EXISTS") {
+			p.acceptWords("IF", "NOT", "EXISTS")
+		}
+		constraints := make([]func(*Table), 0)
+		if err := s.tableElement(p, table, &constraints); err != nil {
+			return err
+		}
+		for _, add := range constraints {
+			add(table)
+		}
+		if !p.acceptPunct(",") {
+			break
+		}
+	}
+	if !p.done() {
+		return p.unexpected("end of the statement")
+	}
+	return nil
+}
+
+// tableElement reads the column or the constraint of the table, the
+// constraints are added after all columns.
+func (s *sqlImport) tableElement(p *sqlParser, table *Table, constraints *[]func(*Table)) error {
+	add := func(f func(*Table)) { *constraints = append(*constraints, f) }
+	name := ""
+	if p.acceptWord("CONSTRAINT") {
+		var err error
+		if name, err = p.name(); err != nil {
+			return err
+		}
+	}
+
+	switch {
+	case p.acceptWords("PRIMARY", "KEY"):
+		p.acceptWord("CLUSTERED", "NONCLUSTERED")
+		columns, err := p.columnNames()
+		if err != nil {
+			return err
+		}
+		add(func(t *Table) { importPrimaryKey(t, columns) })
+	case p.acceptWord("UNIQUE"):
+		p.acceptWord("KEY", "INDEX")
+		p.acceptWord("CLUSTERED", "NONCLUSTERED")
+		if !p.isPunct("(") {
+			// the index name of mariadb, e.g. UNIQUE KEY uk_doc_ref (ref)
+			var err error
+			if name, err = p.name(); err != nil {
+				return err
+			}
+		}
+		columns, err := p.columnNames()
+		if err != nil {
+			return err
+		}
+		add(func(t *Table) { importUnique(t, name, columns) })
+	case p.acceptWords("FOREIGN", "KEY"):
+		if !p.isPunct("(") {
+			if _, err := p.name(); err != nil {
+				return err
+			}
+		}
+		columns, err := p.columnNames()
+		if err != nil {
+			return err
+		}
+		if !p.acceptWord("REFERENCES") {
+			return p.unexpected("REFERENCES")
+		}
+		fk, err := p.references()
+		if err != nil {
+			return err
+		}
+		fk.Name, fk.Columns = name, columns
+		add(func(t *Table) { s.addForeignKey(t, fk) })
+	case p.acceptWord("CHECK"):
+		expr, err := p.parens()
+		if err != nil {
+			return err
+		}
+		add(func(t *Table) { importCheck(t, Check{Name: name, Expr: expr}) })
+	case name == "" && p.isWord("KEY", "INDEX"):
+		// the index of mariadb, e.g. KEY idx_doc_ref (ref)
+		p.next()
+		if !p.isPunct("(") {
+			var err error
+			if name, err = p.name(); err != nil {
+				return err
+			}
+		}
+		columns, expression, err := p.indexColumns()
+		if err != nil {
+			return err
+		}
+		p.skip()
+		if expression {
+			s.warn(table.Name, "index '"+name+"' on the expression is not imported")
+			return nil
+		}
+		add(func(t *Table) { s.addIndex(t, Index{Name: name, Columns: columns}) })
+	case name == "" && p.isWord("FULLTEXT", "SPATIAL"):
+		s.warn(table.Name, strings.ToLower(p.next().text)+" index is not imported")
+		p.skip()
+	case p.isWord("EXCLUDE"):
+		s.warn(table.Name, "exclusion constraint '"+name+"' is not imported")
+		p.skip()
+	case name != "":
+		s.warn(table.Name, "constraint '"+name+"' is not imported: "+p.skip())
+	default:
+		return s.column(p, table, add)
+	}
+	return nil
+}
+
+// column reads the column definition, the column is added to the table and
+// the constraints of the column are added by add.
+func (s *sqlImport) column(p *sqlParser, table *Table, add func(func(*Table))) error {
+	name, err := p.name()
+	if err != nil {
+		return err
+	}
+	if findColumn(table, name) != nil {
+		// e.g. ALTER TABLE ADD COLUMN IF NOT EXISTS of the existing column
+		p.skip()
+		return nil
+	}
+	column := Column{Name: name}
+
+	// the data type, e.g. VARCHAR(50), INT UNSIGNED, TIMESTAMP WITH TIME ZONE
+	constraintWords := []string{"NOT", "NULL", "DEFAULT", "AUTO_INCREMENT", "IDENTITY", "GENERATED", "PRIMARY", "UNIQUE",
+		"REFERENCES", "CHECK", "COMMENT", "CONSTRAINT", "COLLATE", "CHARSET", "ON", "AS", "AFTER", "FIRST", "KEY",
+		"INVISIBLE", "ROWGUIDCOL", "SPARSE", "FILESTREAM"}
+	parts := make([]string, 0)
+	for !p.isEnd() && !p.isWord(constraintWords...) && !p.isWords("CHARACTER", "SET") {
+		switch tok := p.peek(); {
+		case tok.kind == sqlPunct && (tok.text == "(" || tok.text == "[" || tok.text == "]") && len(parts) > 0:
+			start := p.pos
+			if tok.text == "(" {
+				p.group()
+			} else {
+				p.pos++
+			}
+			parts[len(parts)-1] += strings.ReplaceAll(p.text(start), " ", "")
+		default:
+			parts = append(parts, p.next().text)
+		}
+	}
+	column.DataType = strings.Join(parts, " ")
+	switch s.dialect {
+	case DialectPostgres:
+		if t, found := serialTypes[strings.ToUpper(column.DataType)]; found {
+			column.DataType, column.Identity = t, "Y"
+		}
+		column.DataType = pgImportType(strings.ToLower(column.DataType))
+	case DialectMariaDB:
+		column.DataType = mariadbImportType(column.DataType)
+	default:
+		column.DataType = importType(column.DataType)
+	}
+
+	for !p.isEnd() {
+		constraint := ""
+		if p.acceptWord("CONSTRAINT") {
+			if constraint, err = p.name(); err != nil {
+				return err
+			}
+		}
+		switch {
+		case p.acceptWords("NOT", "NULL"):
+			column.NotNull = "Y"
+		case p.acceptWord("NULL"):
+		case p.acceptWord("DEFAULT"):
+			column.Value = s.defaultValue(p.skip(constraintWords...), column.DataType)
+		case p.acceptWord("AUTO_INCREMENT", "IDENTITY"):
+			column.Identity = "Y"
+			if p.isPunct("(") {
+				p.group()
+			}
+		case p.acceptWord("GENERATED"):
+			p.acceptWord("ALWAYS")
+			p.acceptWords("BY", "DEFAULT")
+			p.acceptWord("AS")
+			if p.acceptWord("IDENTITY") {
+				column.Identity = "Y"
+				if p.isPunct("(") {
+					p.group()
+				}
+				break
+			}
+			fallthrough
+		case p.acceptWord("AS"):
+			// the computed column
+			p.skip(constraintWords...)
+			p.acceptWord("STORED", "VIRTUAL", "PERSISTED", "PERSISTENT")
+			s.warn(table.Name, "generated column '"+name+"' is imported as a plain column")
+		case p.acceptWords("PRIMARY", "KEY"):
+			p.acceptWord("CLUSTERED", "NONCLUSTERED")
+			p.acceptWord("ASC", "DESC")
+			add(func(t *Table) { importPrimaryKey(t, []string{name}) })
+		case p.acceptWord("UNIQUE"):
+			p.acceptWord("KEY")
+			p.acceptWord("CLUSTERED", "NONCLUSTERED")
+			add(func(t *Table) { importUnique(t, constraint, []string{name}) })
+		case p.acceptWord("REFERENCES"):
+			fk, err := p.references()
+			if err != nil {
+				return err
+			}
+			fk.Name, fk.Columns = constraint, []string{name}
+			add(func(t *Table) { s.addForeignKey(t, fk) })
+		case p.acceptWord("CHECK"):
+			expr, err := p.parens()
+			if err != nil {
+				return err
+			}
+			add(func(t *Table) { importCheck(t, Check{Name: constraint, Expr: expr}) })
+		case p.acceptWord("COMMENT"):
+			if p.peek().kind == sqlString {
+				column.Desc = p.next().text
+			}
+		case p.acceptWords("ON", "UPDATE"):
+			s.warn(table.Name, "ON UPDATE "+p.skip(constraintWords...)+" of the column '"+name+"' is not imported")
+		default:
+			// the options not in the definition, e.g. COLLATE utf8mb4_bin, AFTER ver
+			p.next()
+		}
+	}
+	table.Columns = append(table.Columns, column)
+	return nil
+}
+
+// defaultValue returns the default value of the column from the expression,
+// the quoted number of a non-text column is the number, e.g. DEFAULT '0'.
+func (s *sqlImport) defaultValue(expr string, dataType string) string {
+	expr = trimParens(expr)
+	switch {
+	case s.dialect == DialectMSSQL && len(expr) > 2 && (expr[0] == 'N' || expr[0] == 'n') && expr[1] == '\'':
+		expr = expr[1:]
+	case s.dialect == DialectMariaDB:
+		expr = strings.ReplaceAll(expr, `\'`, "''")
+	}
+	value := lo.Ternary(s.dialect == DialectPostgres, pgImportDefault(expr), importDefault(expr))
+	if len(value) > 2 && value[0] == '\'' && value[len(value)-1] == '\'' &&
+		numberRegexp.MatchString(value[1:len(value)-1]) && sqliteType(dataType) != "TEXT" {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// addForeignKey adds the foreign key to the table, the default name of the
+// mssql templates is omitted.
+func (s *sqlImport) addForeignKey(table *Table, fk ForeignKey) {
+	if s.dialect == DialectMSSQL && fk.Name == mssqlFkName(table.Name, ForeignKey{Columns: fk.Columns}) {
+		fk.Name = ""
+	}
+	importForeignKey(table, fk)
+}
+
+// addIndex adds the index to the table, the default name of the mssql
+// templates is omitted.
+func (s *sqlImport) addIndex(table *Table, ix Index) {
+	if s.dialect == DialectMSSQL && ix.Name == mssqlIndexName(table.Name, Index{Columns: ix.Columns}) {
+		ix.Name = ""
+	}
+	importIndex(table, ix)
+}
+
+// createIndex reads CREATE INDEX, the index is added to the table.
+func (s *sqlImport) createIndex(p *sqlParser) error {
+	ix := Index{Unique: p.acceptWord("UNIQUE")}
+	p.acceptWord("CLUSTERED", "NONCLUSTERED")
+	if !p.acceptWord("INDEX") {
+		return p.unexpected("INDEX")
+	}
+	p.acceptWord("CONCURRENTLY")
+	p.acceptWords("IF", "NOT", "EXISTS")
+	if !p.isWord("ON") {
+		var err error
+		if ix.Name, err = p.name(); err != nil {
+			return err
+		}
+	}
+	if !p.acceptWord("ON") {
+		return p.unexpected("ON")
+	}
+	p.acceptWord("ONLY")
+	schema, name, err := p.tableName()
+	if err != nil {
+		return err
+	}
+	method := ""
+	if p.acceptWord("USING") {
+		method = strings.ToLower(p.next().text)
+	}
+	columns, expression, err := p.indexColumns()
+	if err != nil {
+		return err
+	}
+	ix.Columns = columns
+	for !p.done() {
+		switch {
+		case p.acceptWord("INCLUDE"):
+			if ix.Include, err = p.columnNames(); err != nil {
+				return err
+			}
+		case p.acceptWord("WHERE"):
+			ix.Where = strings.TrimSpace(p.skip("WITH", "ON", "TABLESPACE"))
+		default:
+			p.next()
+		}
+	}
+
+	table := s.findTable(schema, name)
+	switch {
+	case table == nil:
+		return tracerr.Errorf("table '%s' not found", name)
+	case expression:
+		s.warn(table.Name, "index '"+ix.Name+"' on the expression is not imported")
+		return nil
+	case method != "" && method != "btree":
+		s.warn(table.Name, "index '"+ix.Name+"' is imported without the method "+method)
+	}
+	s.addIndex(table, ix)
+	return nil
+}
+
+// comment reads COMMENT ON TABLE and COMMENT ON COLUMN of postgres.
+func (s *sqlImport) comment(p *sqlParser) error {
+	if !p.acceptWord("ON") {
+		return p.unexpected("ON")
+	}
+	object := strings.ToUpper(p.next().text)
+	if object != "TABLE" && object != "COLUMN" {
+		return tracerr.Errorf("comment on %s is not supported", strings.ToLower(object))
+	}
+	names, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !p.acceptWord("IS") {
+		return p.unexpected("IS")
+	}
+	desc := ""
+	if p.peek().kind == sqlString {
+		desc = p.next().text
+	} else if !p.acceptWord("NULL") {
+		return p.unexpected("string")
+	}
+
+	column := ""
+	if object == "COLUMN" {
+		if len(names) < 2 {
+			return tracerr.New("table of the column not found")
+		}
+		column, names = names[len(names)-1], names[:len(names)-1]
+	}
+	return s.setComment(lo.Ternary(len(names) > 1, names[0], ""), names[len(names)-1], column, desc)
+}
+
+// extendedProperty reads sp_addextendedproperty of the MS_Description of the
+// table or column of mssql, e.g. EXEC sp_addextendedproperty @name =
+// N'MS_Description', @value = N'document', @level0type = N'SCHEMA',
+// @level0name = N'dbo', @level1type = N'TABLE', @level1name = N'doc'.
+func (s *sqlImport) extendedProperty(p *sqlParser) error {
+	names, err := p.qualifiedName()
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(names[len(names)-1], "sp_addextendedproperty") {
+		return tracerr.New("unsupported procedure")
+	}
+	params := []string{"@name", "@value", "@level0type", "@level0name", "@level1type", "@level1name", "@level2type", "@level2name"}
+	args := make(map[string]string)
+	for i := 0; !p.done(); i++ {
+		param := ""
+		if tok := p.peek(); tok.kind == sqlWord && strings.HasPrefix(tok.text, "@") {
+			param = strings.ToLower(p.next().text)
+			if !p.acceptPunct("=") {
+				return p.unexpected("=")
+			}
+		} else if i < len(params) {
+			param = params[i]
+		}
+		args[param] = p.next().text
+		if !p.acceptPunct(",") {
+			break
+		}
+	}
+	if !strings.EqualFold(args["@name"], "MS_Description") {
+		return tracerr.New("only MS_Description is supported")
+	}
+	if !strings.EqualFold(args["@level1type"], "TABLE") {
+		return tracerr.Errorf("description of %s is not supported", strings.ToLower(args["@level1type"]))
+	}
+	column := lo.Ternary(strings.EqualFold(args["@level2type"], "COLUMN"), args["@level2name"], "")
+	return s.setComment(args["@level0name"], args["@level1name"], column, args["@value"])
+}
+
+// setComment sets the description of the table, or the column if not empty.
+func (s *sqlImport) setComment(schema string, name string, column string, desc string) error {
+	table := s.findTable(schema, name)
+	if table == nil {
+		return tracerr.Errorf("table '%s' not found", name)
+	}
+	if column == "" {
+		table.Desc = desc
+		return nil
+	}
+	c := findColumn(table, column)
+	if c == nil {
+		return tracerr.Errorf("column '%s.%s' not found", name, column)
+	}
+	c.Desc = desc
+	return nil
+}
@@ -0,0 +1,301 @@
+package transform
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+	"gopkg.in/yaml.v3"
+)
+
+// importTestSQL imports the SQL script of the dialect from a temporary file.
+func importTestSQL(t *testing.T, name string, dialect string, sql string) (*DataDef, []Finding) {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	writeTestFile(t, file, sql)
+	data, findings, err := ImportSQL(file, dialect)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data, findings
+}
+
+// assertDataDef checks the definition equals the yaml definition regardless
+// of the positions.
+func assertDataDef(t *testing.T, got *DataDef, want string) {
+	t.Helper()
+	expected := readTestYml(t, want)
+	clearPositions(got)
+	clearPositions(expected)
+	if !reflect.DeepEqual(got, expected) {
+		gotYml, _ := yaml.Marshal(got)
+		wantYml, _ := yaml.Marshal(expected)
+		t.Errorf("imported definition differs\ngot:\n%s\nwant:\n%s", gotYml, wantYml)
+	}
+}
+
+func TestImportSQL(t *testing.T) {
+	tests := []struct {
+		name     string
+		dialect  string
+		sql      string
+		want     string
+		warnings []string // the messages of the warnings in order
+	}{
+		{
+			name:    "mariadb",
+			dialect: DialectMariaDB,
+			sql: "USE `app`;\n" + `SET NAMES utf8mb4;
+# the tables
+CREATE TABLE IF NOT EXISTS ` + "`doc`" + ` (
+      doc_id INT NOT NULL AUTO_INCREMENT PRIMARY KEY COMMENT 'unique identifier',
+      ref VARCHAR(50) NOT NULL UNIQUE COMMENT 'it''s the reference',
+      kind CHAR(3) NOT NULL DEFAULT 'COM',
+      ` + "`order`" + ` INT DEFAULT '0',
+      CONSTRAINT ck_doc_kind CHECK (kind IN ('COM', 'PRE')),
+      KEY idx_doc_order (` + "`order`" + `)
+) ENGINE=InnoDB COMMENT='documents';
+CREATE TABLE IF NOT EXISTS tag (
+      tag_id INT NOT NULL,
+      doc_id INT,
+      PRIMARY KEY (tag_id),
+      CONSTRAINT fk_tag_doc FOREIGN KEY (doc_id) REFERENCES doc (doc_id) ON DELETE CASCADE
+);
+ALTER TABLE IF EXISTS tag ADD CONSTRAINT ck_tag_id CHECK (tag_id > 0);
+CREATE UNIQUE INDEX IF NOT EXISTS ux_tag_doc ON tag (doc_id, tag_id DESC);
+CREATE VIEW v_doc AS SELECT * FROM doc;
+DROP TABLE IF EXISTS tag;
+`,
+			want: `schemas:
+  - name: app
+    tables:
+      - name: doc
+        desc: documents
+        columns:
+          - {na: doc_id, ty: INT, id: Y, nu: Y, dc: unique identifier}
+          - {na: ref, ty: VARCHAR(50), nu: Y, un: Y, dc: it's the reference}
+          - {na: kind, ty: CHAR(3), nu: Y, va: COM, enum: [COM, PRE]}
+          - {na: order, ty: INT, va: "0"}
+        indexes:
+          - {columns: [order]}
+      - name: tag
+        columns:
+          - {na: tag_id, ty: INT, nu: Y}
+          - {na: doc_id, ty: INT}
+        primary_key: [tag_id]
+        foreign_keys:
+          - {name: fk_tag_doc, columns: [doc_id], ref_table: doc, ref_columns: [doc_id], on_delete: CASCADE}
+        indexes:
+          - {name: ux_tag_doc, columns: [doc_id, tag_id DESC], unique: true}
+        checks:
+          - {name: ck_tag_id, expr: tag_id > 0}
+`,
+			warnings: []string{"statement is not imported, unsupported statement: CREATE VIEW v_doc AS SELECT * FROM doc"},
+		},
+		{
+			name:    "postgres",
+			dialect: DialectPostgres,
+			sql: `CREATE SCHEMA IF NOT EXISTS app;
+CREATE TABLE IF NOT EXISTS app.doc (
+    doc_id SERIAL PRIMARY KEY,
+    "Ref" VARCHAR(50) NOT NULL CONSTRAINT doc_ref_key UNIQUE,
+    kind CHAR(3) NOT NULL DEFAULT 'COM'::bpchar CHECK (kind IN ('COM', 'PRE')),
+    created TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE TABLE IF NOT EXISTS app.tag (
+    doc_id INTEGER NOT NULL REFERENCES app.doc (doc_id) ON UPDATE CASCADE,
+    code VARCHAR(10) NOT NULL,
+    CONSTRAINT pk_tag PRIMARY KEY (doc_id, code)
+);
+ALTER TABLE ONLY app.tag ADD CONSTRAINT ck_tag_code CHECK (code <> '');
+CREATE INDEX IF NOT EXISTS idx_tag_code ON app.tag USING btree (code) INCLUDE (doc_id) WHERE code IS NOT NULL;
+COMMENT ON TABLE app.doc IS 'documents';
+COMMENT ON COLUMN app.doc."Ref" IS 'reference';
+CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUAGE sql;
+`,
+			want: `schemas:
+  - name: app
+    tables:
+      - name: doc
+        desc: documents
+        columns:
+          - {na: doc_id, ty: INTEGER, id: Y, nu: Y}
+          - {na: Ref, ty: VARCHAR(50), nu: Y, un: Y, dc: reference}
+          - {na: kind, ty: CHAR(3), nu: Y, va: COM}
+          - {na: created, ty: TIMESTAMP, va: CURRENT_TIMESTAMP}
+        checks:
+          - {expr: "kind IN ('COM', 'PRE')"}
+      - name: tag
+        columns:
+          - {na: doc_id, ty: INTEGER, nu: Y}
+          - {na: code, ty: VARCHAR(10), nu: Y}
+        primary_key: [doc_id, code]
+        foreign_keys:
+          - {columns: [doc_id], ref_table: doc, ref_columns: [doc_id], on_update: CASCADE}
+        indexes:
+          - {columns: [code], where: code IS NOT NULL, include: [doc_id]}
+        checks:
+          - {name: ck_tag_code, expr: code <> ''}
+`,
+			warnings: []string{"statement is not imported, unsupported statement: CREATE FUNCTION f() RETURNS int AS $$ SELECT 1; $$ LANGUA..."},
+		},
+		{
+			name:    "mssql",
+			dialect: DialectMSSQL,
+			sql: `IF OBJECT_ID(N'doc', N'U') IS NULL
+BEGIN
+CREATE TABLE [doc] (
+      doc_id INT IDENTITY(1,1) NOT NULL,
+      [ref] NVARCHAR(50) NOT NULL,
+      kind CHAR(3) DEFAULT N'COM' NOT NULL,
+      CONSTRAINT pkdocdoc_id PRIMARY KEY (doc_id),
+      CONSTRAINT uqdocref UNIQUE (ref)
+)
+END;
+GO
+CREATE TABLE tag (
+      tag_id INT NOT NULL PRIMARY KEY,
+      doc_id INT
+);
+ALTER TABLE tag WITH CHECK ADD CONSTRAINT fktagdoc_id FOREIGN KEY (doc_id) REFERENCES doc (doc_id) ON DELETE SET NULL;
+CREATE NONCLUSTERED INDEX idxtagdoc_id ON tag (doc_id) INCLUDE (tag_id);
+EXEC sp_addextendedproperty 'MS_Description', N'documents', 'SCHEMA', 'dbo', 'TABLE', 'doc';
+EXEC sp_addextendedproperty 'MS_Description', N'reference', 'SCHEMA', 'dbo', 'TABLE', 'doc', 'COLUMN', 'ref';
+GRANT SELECT ON doc TO app;
+`,
+			want: `schemas:
+  - name: dbo
+    tables:
+      - name: doc
+        desc: documents
+        columns:
+          - {na: doc_id, ty: INT, id: Y, nu: Y}
+          - {na: ref, ty: NVARCHAR(50), nu: Y, un: Y, dc: reference}
+          - {na: kind, ty: CHAR(3), nu: Y, va: COM}
+      - name: tag
+        columns:
+          - {na: tag_id, ty: INT, nu: Y}
+          - {na: doc_id, ty: INT}
+        primary_key: [tag_id]
+        foreign_keys:
+          - {columns: [doc_id], ref_table: doc, ref_columns: [doc_id], on_delete: SET NULL}
+        indexes:
+          - {columns: [doc_id], include: [tag_id]}
+`,
+			warnings: []string{"statement is not imported, unsupported statement: GRANT SELECT ON doc TO app"},
+		},
+		{
+			name:    "sqlite",
+			dialect: DialectSqlite,
+			sql: `PRAGMA foreign_keys = ON;
+CREATE TABLE IF NOT EXISTS "doc" (
+    doc_id INTEGER PRIMARY KEY AUTOINCREMENT NOT NULL,
+    [ref] TEXT NOT NULL UNIQUE,
+    ` + "`kind`" + ` TEXT NOT NULL DEFAULT 'COM',
+    CONSTRAINT ck_doc_kind CHECK (kind IN ('COM', 'PRE'))
+);
+CREATE TABLE IF NOT EXISTS tag (
+    doc_id INTEGER NOT NULL,
+    code TEXT NOT NULL,
+    CONSTRAINT pk_tag PRIMARY KEY (doc_id, code),
+    CONSTRAINT fk_tag_doc_id FOREIGN KEY (doc_id) REFERENCES doc (doc_id)
+);
+CREATE INDEX IF NOT EXISTS idx_tag_code ON tag (code) WHERE code <> '';
+CREATE TRIGGER tr_doc AFTER INSERT ON doc BEGIN SELECT 1; END;
+`,
+			want: `schemas:
+  - name: main
+    tables:
+      - name: doc
+        columns:
+          - {na: doc_id, ty: INTEGER, id: Y, nu: Y}
+          - {na: ref, ty: TEXT, nu: Y, un: Y}
+          - {na: kind, ty: TEXT, nu: Y, va: COM, enum: [COM, PRE]}
+      - name: tag
+        columns:
+          - {na: doc_id, ty: INTEGER, nu: Y, fk: doc.doc_id}
+          - {na: code, ty: TEXT, nu: Y}
+        primary_key: [doc_id, code]
+        indexes:
+          - {columns: [code], where: code <> ''}
+`,
+			warnings: []string{"statement is not imported, unsupported statement: CREATE TRIGGER tr_doc AFTER INSERT ON doc BEGIN SELECT 1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, findings := importTestSQL(t, "app.sql", tt.dialect, tt.sql)
+			assertDataDef(t, data, tt.want)
+			got := lo.Map(findings, func(f Finding, _ int) string { return f.Message })
+			if strings.Join(got, "\n") != strings.Join(tt.warnings, "\n") {
+				t.Errorf("warnings =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.warnings, "\n"))
+			}
+			for _, f := range findings {
+				if f.Severity != SeverityWarning || f.Pos.Line == 0 {
+					t.Errorf("unexpected finding %v", f)
+				}
+			}
+		})
+	}
+}
+
+func TestImportSQLWarningPosition(t *testing.T) {
+	_, findings := importTestSQL(t, "app.sql", DialectPostgres, `CREATE TABLE doc (doc_id INT);
+
+  CREATE VIEW v_doc AS SELECT * FROM doc;
+`)
+	if len(findings) != 1 {
+		t.Fatalf("findings = %v", findings)
+	}
+	if pos := findings[0].Pos; pos.Line != 3 || pos.Col != 3 || filepath.Base(pos.File) != "app.sql" {
+		t.Errorf("warning position = %v", pos)
+	}
+}
+
+func TestImportSQLSample(t *testing.T) {
+	data, findings, err := ImportSQL(filepath.Join("..", "example", "sample.sql"), DialectMariaDB)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) > 0 {
+		t.Errorf("unexpected findings %v", findings)
+	}
+	record := `
+          - {na: deleted, ty: INT, nu: Y, va: "0", dc: "0:activated, others:deleted"}
+          - {na: create_user_id, ty: INT, nu: Y, dc: record create user ID}
+          - {na: create_time, ty: DATETIME, nu: Y, dc: record create time}
+          - {na: update_user_id, ty: INT, nu: Y, dc: record update user ID}
+          - {na: update_time, ty: DATETIME, nu: Y, dc: record update time}
+          - {na: recver, ty: INT, nu: Y, va: "0", dc: record version}`
+	assertDataDef(t, data, `schemas:
+  - name: sample
+    tables:
+      - name: doc
+        columns:
+          - {na: doc_id, ty: INT, id: Y, nu: Y, dc: unique identifier}
+          - {na: ver, ty: INT, nu: Y, dc: "version, starts from zero"}
+          - {na: ref, ty: VARCHAR(50), nu: Y, dc: reference to locate the external file}`+record+`
+      - name: doc_tag
+        columns:
+          - {na: doc_tag_id, ty: INT, id: Y, nu: Y, dc: unique identifier}
+          - {na: doc_id, ty: INT, nu: Y, fk: doc.doc_id, dc: doc id}
+          - {na: tag_id, ty: INT, nu: Y, fk: tag.tag_id, dc: tag id}`+record+`
+      - name: tag
+        columns:
+          - {na: tag_id, ty: INT, id: Y, nu: Y, dc: unique identifier}
+          - {na: code, ty: VARCHAR(10), dc: tag code. eg. premarket md application index A1}
+          - {na: name, ty: VARCHAR(20), nu: Y, dc: tag name}
+          - {na: priority, ty: VARCHAR(50), dc: tag priority}
+          - {na: descr, ty: VARCHAR(50), dc: tag description}
+          - {na: source, ty: CHAR(3), nu: Y, dc: "Source; COM: common, PRE: pre, POS: post"}
+          - {na: type, ty: CHAR(3), nu: Y, dc: "Tag Type; ATT: attachment, APP: application, COM: company"}`+record+`
+`)
+}
+
+func TestImportSQLUnsupportedDialect(t *testing.T) {
+	if _, _, err := ImportSQL("app.sql", "oracle"); err == nil {
+		t.Error("expected an error for the unsupported dialect")
+	}
+}
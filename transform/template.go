@@ -0,0 +1,203 @@
+package transform
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/CloudyKit/jet/v6"
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+
+	"dst/template"
+)
+
+// TemplateExt is the file extension of the templates.
+const TemplateExt = ".tpl"
+
+// TemplateInfo describes an available template.
+type TemplateInfo struct {
+	Name   string // name without the extension, e.g. mariadb
+	Source string // file path of the user template, or "built-in"
+}
+
+// fsLoader is the jet loader of the file system, e.g. the embedded templates.
+type fsLoader struct {
+	fsys fs.FS
+}
+
+func (l *fsLoader) Exists(templatePath string) bool {
+	_, err := fs.Stat(l.fsys, strings.TrimPrefix(templatePath, "/"))
+	return err == nil
+}
+
+func (l *fsLoader) Open(templatePath string) (io.ReadCloser, error) {
+	return l.fsys.Open(strings.TrimPrefix(templatePath, "/"))
+}
+
+// UserTemplateDirs returns the directories of the user templates, which are
+// the directories in DST_TEMPLATE_PATH environment variable and
+// <user config dir>/dst/template. The user templates override the built-in
+// templates of the same name.
+func UserTemplateDirs() []string {
+	dirs := make([]string, 0)
+	if env := os.Getenv("DST_TEMPLATE_PATH"); env != "" {
+		dirs = append(dirs, filepath.SplitList(env)...)
+	}
+	if dir, err := os.UserConfigDir(); err == nil {
+		dirs = append(dirs, filepath.Join(dir, "dst", "template"))
+	}
+	return dirs
+}
+
+// Templates returns the available templates sorted by name, a user template
+// replaces the built-in template of the same name.
+func Templates() ([]TemplateInfo, error) {
+	found := make(map[string]TemplateInfo)
+
+	builtins, err := fs.Glob(template.FS, "*"+TemplateExt)
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	for _, file := range builtins {
+		name := strings.TrimSuffix(file, TemplateExt)
+		found[name] = TemplateInfo{Name: name, Source: "built-in"}
+	}
+
+	// the earlier directory has higher priority
+	dirs := UserTemplateDirs()
+	for i := len(dirs) - 1; i >= 0; i-- {
+		files, _ := filepath.Glob(filepath.Join(dirs[i], "*"+TemplateExt))
+		for _, file := range files {
+			name := strings.TrimSuffix(filepath.Base(file), TemplateExt)
+			found[name] = TemplateInfo{Name: name, Source: file}
+		}
+	}
+
+	result := lo.Values(found)
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// resolveTemplate returns the jet loader and the template path of the name,
+// the name is either a template file or the name of a user or built-in
+// template (with or without the extension).
+func resolveTemplate(name string) (jet.Loader, string, error) {
+	if info, err := os.Stat(name); err == nil && !info.IsDir() {
+		return jet.NewOSFileSystemLoader(filepath.Dir(name)), filepath.Base(name), nil
+	}
+
+	file := strings.TrimSuffix(name, TemplateExt) + TemplateExt
+	for _, dir := range UserTemplateDirs() {
+		if info, err := os.Stat(filepath.Join(dir, file)); err == nil && !info.IsDir() {
+			return jet.NewOSFileSystemLoader(dir), file, nil
+		}
+	}
+	if _, err := fs.Stat(template.FS, file); err == nil {
+		return &fsLoader{fsys: template.FS}, file, nil
+	}
+
+	names := make([]string, 0)
+	if templates, err := Templates(); err == nil {
+		names = lo.Map(templates, func(t TemplateInfo, _ int) string { return t.Name })
+	}
+	return nil, "", tracerr.Errorf("template '%s' not found, available templates: %s", name, strings.Join(names, ", "))
+}
+
+// getTemplate returns the parsed template of the name, see resolveTemplate,
+// the logical types are mapped by the type mappings.
+func getTemplate(name string, types TypeMappings) (*jet.Template, error) {
+	loader, file, err := resolveTemplate(name)
+	if err != nil {
+		return nil, err
+	}
+	// the outputs are not HTML, write the values without escaping
+	views := jet.NewSet(loader, jet.WithSafeWriter(nil))
+	addTemplateFuncs(views, types)
+	view, err := views.GetTemplate(path.Join("/", file))
+	if err != nil {
+		return nil, tracerr.Wrap(err)
+	}
+	return view, nil
+}
+
+// addTemplateFuncs adds the functions available in the templates, types are
+// the type mappings of sqlType.
+func addTemplateFuncs(views *jet.Set, types TypeMappings) {
+	// sqlType(column, "mariadb") returns the data type of the column in the dialect
+	views.AddGlobal("sqlType", func(column Column, dialect string) string {
+		return MapType(column.Type(), dialect, types)
+	})
+	// join(list, ", ") and contains(list, "name") for the string lists, e.g. the primary key
+	views.AddGlobal("join", strings.Join)
+	views.AddGlobal("contains", func(list []string, s string) bool {
+		return lo.Contains(list, s)
+	})
+	// sqlString(s) returns the quoted SQL string literal, e.g. 'insurer''s address'
+	views.AddGlobal("sqlString", quoteString)
+	// pascal("doc_tag") returns DocTag, quote(s) returns the quoted string, e.g. for the code generators
+	views.AddGlobal("pascal", pascalCase)
+	views.AddGlobal("quote", strconv.Quote)
+	// logicalType(column) returns the parsed type of the column, e.g. .Name, .Length
+	views.AddGlobal("logicalType", func(column Column) Type {
+		return column.Type()
+	})
+	// sortTables(.) returns the tables in the order of the foreign key dependency
+	// (.Schema and .Table), reverseTables(list) returns them in the drop order
+	views.AddGlobal("sortTables", func(data DataDef) []TableRef {
+		return SortTables(&data)
+	})
+	views.AddGlobal("reverseTables", ReverseTables)
+}
+
+// pascalCase returns the name in pascal case, the words are separated by the
+// non-alphanumeric characters, e.g. doc_tag is DocTag.
+func pascalCase(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var sb strings.Builder
+	for _, word := range words {
+		runes := []rune(word)
+		sb.WriteString(strings.ToUpper(string(runes[0])) + string(runes[1:]))
+	}
+	return sb.String()
+}
+
+// ExportTemplate writes the content of the template to the out file, or
+// standard output if out is empty. It fails if the out file exists.
+func ExportTemplate(name string, out string) error {
+	loader, file, err := resolveTemplate(name)
+	if err != nil {
+		return err
+	}
+	rc, err := loader.Open(path.Join("/", file))
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
+	defer rc.Close()
+
+	if out == "" || out == "stdout" {
+		_, err := io.Copy(os.Stdout, rc)
+		return tracerr.Wrap(err)
+	}
+	if _, err := os.Stat(out); err == nil {
+		return tracerr.Errorf("file '%s' already exists", out)
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return tracerr.Wrap(err)
+	}
+	fh, err := os.Create(out)
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
+	defer fh.Close()
+	if _, err := io.Copy(fh, rc); err != nil {
+		return tracerr.Wrap(err)
+	}
+	return nil
+}
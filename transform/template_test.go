@@ -0,0 +1,200 @@
+package transform
+
+import (
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+
+	"dst/template"
+)
+
+const indexesYml = `schemas:
+  - name: app
+    tables:
+      - name: doc
+        columns:
+          - {na: doc_id, ty: INT, id: Y, nu: Y}
+          - {na: code, ty: VARCHAR(10)}
+          - {na: title, ty: VARCHAR(50)}
+        indexes:
+          - {name: ix_doc_code, columns: [code], include: [title]}
+          - {name: ix_doc_title, columns: [title], where: "code IS NOT NULL"}
+`
+
+func TestWriteTplMariadbIndexes(t *testing.T) {
+	data := readTestYml(t, indexesYml)
+	script := readTestOutput(t, "mariadb.sql", func(out string) error {
+		return WriteTpl(data, "mariadb", out, "", nil)
+	})
+	assertInOrder(t, script,
+		"CREATE INDEX IF NOT EXISTS ix_doc_code ON doc (code);",
+		"-- ix_doc_code included columns skipped, INCLUDE is not supported: title",
+		"-- ix_doc_title skipped, partial index is not supported: WHERE code IS NOT NULL",
+	)
+}
+
+// userTemplateDir sets the user template directory to a temporary directory
+// with the files.
+func userTemplateDir(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		writeTestFile(t, filepath.Join(dir, name), content)
+	}
+	t.Setenv("DST_TEMPLATE_PATH", dir)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	return dir
+}
+
+func TestTemplates(t *testing.T) {
+	dir := userTemplateDir(t, map[string]string{
+		"mariadb.tpl": "user mariadb",
+		"custom.tpl":  "custom",
+		"notes.txt":   "not a template",
+	})
+	templates, err := Templates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := strings.Join(lo.Map(templates, func(info TemplateInfo, _ int) string {
+		return info.Name + " " + strings.TrimPrefix(info.Source, dir+string(filepath.Separator))
+	}), "\n")
+	want := strings.Join([]string{
+		"custom custom.tpl",
+		"erd built-in",
+		"go-enum built-in",
+		"mariadb mariadb.tpl",
+		"mssql-alter built-in",
+		"mssql-create built-in",
+	}, "\n")
+	if got != want {
+		t.Errorf("templates =\n%s\nwant\n%s", got, want)
+	}
+
+	// the user template overrides the built-in one
+	data := readTestYml(t, indexesYml)
+	if out := readTestOutput(t, "s.sql", func(out string) error { return WriteTpl(data, "mariadb", out, "", nil) }); out != "user mariadb" {
+		t.Errorf("output = %q, want the user template", out)
+	}
+}
+
+func TestExportTemplate(t *testing.T) {
+	userTemplateDir(t, map[string]string{"mariadb.tpl": "user mariadb"})
+	builtin, err := fs.ReadFile(template.FS, "erd.tpl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct{ name, want string }{
+		{"erd", string(builtin)},
+		{"erd.tpl", string(builtin)},
+		{"mariadb", "user mariadb"},
+	}
+	for _, tt := range tests {
+		if got := readTestOutput(t, "out.tpl", func(out string) error { return ExportTemplate(tt.name, out) }); got != tt.want {
+			t.Errorf("%s: exported %q, want %q", tt.name, got, tt.want)
+		}
+	}
+
+	out := filepath.Join(t.TempDir(), "erd.tpl")
+	writeTestFile(t, out, "mine")
+	if err := ExportTemplate("erd", out); err == nil {
+		t.Error("expected an error for the existing file")
+	}
+	err = ExportTemplate("unknown", filepath.Join(t.TempDir(), "unknown.tpl"))
+	want := "template 'unknown' not found, available templates: erd, go-enum, mariadb, mssql-alter, mssql-create"
+	if err == nil || tracerr.Unwrap(err).Error() != want {
+		t.Errorf("error = %v, want %s", err, want)
+	}
+}
+
+func TestWriteTplPrimaryKey(t *testing.T) {
+	data := readTestYml(t, `schemas:
+  - name: app
+    tables:
+      - name: doc_tag
+        primary_key: [doc_id, tag_id]
+        columns:
+          - { na: doc_id, ty: INT, nu: Y }
+          - { na: tag_id, ty: INT, nu: Y }
+          - { na: seq, ty: INT, nu: Y }
+`)
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"mariadb", []string{"seq INT NOT NULL,", "PRIMARY KEY (doc_id, tag_id)\n);"}},
+		{"mssql-create", []string{"seq INT NOT NULL,", "CONSTRAINT pkdoc_tagdoc_idtag_id PRIMARY KEY (doc_id, tag_id)\n)"}},
+		{"erd", []string{"| <size:11>PK</size> | <size:11>doc_id</size>", "| <size:11>PK</size> | <size:11>tag_id</size>"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			script := readTestOutput(t, "out.txt", func(out string) error { return WriteTpl(data, tt.template, out, "", nil) })
+			assertInOrder(t, script, tt.want...)
+			if strings.Contains(script, "AUTO_INCREMENT") || strings.Contains(script, "IDENTITY") {
+				t.Errorf("the primary key is not the identity:\n%s", script)
+			}
+		})
+	}
+}
+
+func TestWriteTplForeignKeys(t *testing.T) {
+	data := readTestYml(t, `schemas:
+  - name: app
+    tables:
+      - name: tag
+        primary_key: [code, lang]
+        columns:
+          - { na: code, ty: VARCHAR(10), nu: Y }
+          - { na: lang, ty: CHAR(2), nu: Y }
+      - name: doc_tag
+        columns:
+          - { na: doc_id, ty: INT, nu: Y }
+          - { na: tag_code, ty: VARCHAR(10), nu: Y }
+          - { na: tag_lang, ty: CHAR(2), nu: Y }
+        foreign_keys:
+          - { name: fk_tag, columns: [tag_code, tag_lang], ref_table: tag, ref_columns: [code, lang], on_delete: cascade, on_update: SET NULL }
+`)
+	tests := []struct {
+		template string
+		want     []string
+	}{
+		{"mariadb", []string{
+			"ALTER TABLE IF EXISTS doc_tag ADD CONSTRAINT fk_tag FOREIGN KEY (tag_code, tag_lang) REFERENCES tag (code, lang) ON DELETE CASCADE ON UPDATE SET NULL;",
+			"ALTER TABLE IF EXISTS doc_tag DROP CONSTRAINT IF EXISTS fk_tag;",
+		}},
+		{"mssql-create", []string{
+			"IF OBJECT_ID(N'fk_tag', N'F') IS NULL ALTER TABLE doc_tag ADD CONSTRAINT fk_tag FOREIGN KEY (tag_code, tag_lang) REFERENCES tag (code, lang) ON DELETE CASCADE ON UPDATE SET NULL;",
+			"IF OBJECT_ID(N'fk_tag', N'F') IS NOT NULL ALTER TABLE doc_tag DROP CONSTRAINT IF EXISTS fk_tag;",
+		}},
+		{"mssql-alter", []string{
+			"IF OBJECT_ID(N'fk_tag', N'F') IS NULL ALTER TABLE doc_tag ADD CONSTRAINT fk_tag FOREIGN KEY (tag_code, tag_lang) REFERENCES tag (code, lang) ON DELETE CASCADE ON UPDATE SET NULL;",
+		}},
+		{"erd", []string{
+			"| <size:11>FK</size> | <size:11>tag_code</size>",
+			"| <size:11>FK</size> | <size:11>tag_lang</size>",
+			`doc_tag }o--|| tag #text:FireBrick : "tag_code, tag_lang"`,
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			script := readTestOutput(t, "out.txt", func(out string) error { return WriteTpl(data, tt.template, out, "", nil) })
+			assertInOrder(t, script, tt.want...)
+		})
+	}
+}
+
+func TestWriteTplGoEnum(t *testing.T) {
+	data := readTestYml(t, checksYml)
+	got := readTestOutput(t, "enum.go", func(out string) error { return WriteTpl(data, "go-enum", out, "", nil) })
+	assertInOrder(t, got,
+		"package model",
+		"// DocKind is the value of doc.kind\ntype DocKind string",
+		"\tDocKindCOM DocKind = \"COM\"\n\tDocKindPRE DocKind = \"PRE\"\n",
+		"// DocState is the value of doc.state\ntype DocState string",
+		"\tDocStateA DocState = \"A\" // active\n\tDocStateD DocState = \"D\" // deleted, it's gone\n",
+	)
+}
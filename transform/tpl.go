@@ -2,47 +2,45 @@ package transform
 
 import (
 	"os"
-	"path/filepath"
 
-	"github.com/CloudyKit/jet/v6"
 	"github.com/ztrue/tracerr"
 )
 
+func init() {
+	Register(Format{
+		Name:    "text",
+		Aliases: []string{"t"},
+		Usage:   "text file generated by the template, e.g. sql",
+		Exts:    []string{".sql", ".txt"},
+		Options: []string{OptTemplate},
+		Writer: WriterFunc(func(data *DataDef, out string, opts Options) error {
+			if opts.Template == "" {
+				return tracerr.Errorf("template is required to output '%s', e.g. --template mariadb", out)
+			}
+			return WriteTpl(data, opts.Template, out, "", opts.Types)
+		}),
+	})
+}
+
 // WriteTpl generates a template using the provided data and template file,
 // and writes the output to the specified file or standard output.
 //
 // Parameters:
 //   - data: A pointer to a DataDef struct containing the data for the template.
-//   - tplf: A string specifying the path to the template file.
+//   - tplf: A string specifying the path to the template file, or the name of
+//     a user or built-in template (e.g. mariadb).
 //   - out: A string specifying the path to the output file. If empty, the output
 //     will be written to standard output.
 //   - pattern: A string specifying a pattern to filter the desired tables from
 //     the data.
+//   - types: The type mappings of the logical types used by sqlType, nil for
+//     the built-in ones.
 //
 // Return:
 // - An error if any occurred during the execution of the function.
-func WriteTpl(data *DataDef, tplf string, out string, pattern string) error {
-
-	// var loader jet.Loader
-	// if _, err := os.Stat(outtpl); errors.Is(err, os.ErrNotExist) {
-	// 	// read the template from embed file store
-	// 	tplFile := path.Join("templates", outtpl)
-	// 	content, err := tpl.ReadFile(tplFile)
-	// 	if err != nil {
-	// 		return eris.Wrapf(err, "failed to read the template %s", outtpl)
-	// 	}
-	// 	// use memory loader
-	// 	inMemloader := jet.NewInMemLoader()
-	// 	inMemloader.Set(path.Join("/", outtpl), string(content))
-	// 	loader = inMemloader
-	// } else {
-	// 	loader = jet.NewOSFileSystemLoader(filepath.Dir(outtpl))
-	// }
-	loader := jet.NewOSFileSystemLoader(filepath.Dir(tplf))
+func WriteTpl(data *DataDef, tplf string, out string, pattern string, types TypeMappings) error {
 
-	// views := jet.NewSet(loader, jet.InDevelopmentMode())
-	views := jet.NewSet(loader)
-	view, err := views.GetTemplate(filepath.Base(tplf))
+	view, err := getTemplate(tplf, types)
 	if err != nil {
 		return tracerr.Wrap(err)
 	}
@@ -0,0 +1,240 @@
+package transform
+
+import (
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+)
+
+// the dialects of the built-in type mappings
+const (
+	DialectMariaDB  = "mariadb"
+	DialectMSSQL    = "mssql"
+	DialectPostgres = "postgres"
+	DialectSqlite   = "sqlite"
+)
+
+// Type is the parsed data type of the column. The logical types are written
+// in lower case, e.g. string(50), int64, decimal(10,2), other data types
+// (e.g. VARCHAR(50)) are raw types passed to the output as is. A raw type of
+// the same name as a logical type is written in upper case, e.g. TEXT.
+type Type struct {
+	Name      string // logical type name, or the raw type without parameters
+	Length    int    // length of string, char and bytes
+	Precision int    // precision of decimal
+	Scale     int    // scale of decimal
+	Logical   bool   // true if it is a logical type
+	Raw       string // the data type defined in the column
+}
+
+// logicalTypes are the logical type names and the number of parameters allowed
+var logicalTypes = map[string]int{
+	"string":    1,
+	"char":      1,
+	"text":      0,
+	"bool":      0,
+	"int16":     0,
+	"int32":     0,
+	"int64":     0,
+	"float32":   0,
+	"float64":   0,
+	"decimal":   2,
+	"date":      0,
+	"time":      0,
+	"timestamp": 0,
+	"uuid":      0,
+	"json":      0,
+	"bytes":     1,
+}
+
+// TypeMappings are the data types of the logical types for each dialect, the
+// placeholders {length}, {precision} and {scale} are replaced by the
+// parameters. The logical type without the parameters uses the mapping of
+// "<name>()" if exists, e.g. string without length is mapped as text.
+type TypeMappings map[string]map[string]string
+
+// defaultTypeMappings are the built-in type mappings of the dialects.
+var defaultTypeMappings = TypeMappings{
+	DialectMariaDB: {
+		"string": "VARCHAR({length})", "string()": "TEXT", "char": "CHAR({length})", "char()": "CHAR(1)", "text": "LONGTEXT",
+		"bool": "TINYINT(1)", "int16": "SMALLINT", "int32": "INT", "int64": "BIGINT", "float32": "FLOAT", "float64": "DOUBLE",
+		"decimal": "DECIMAL({precision},{scale})", "decimal()": "DECIMAL",
+		"date": "DATE", "time": "TIME", "timestamp": "DATETIME", "uuid": "CHAR(36)", "json": "JSON",
+		"bytes": "VARBINARY({length})", "bytes()": "LONGBLOB",
+	},
+	DialectMSSQL: {
+		"string": "NVARCHAR({length})", "string()": "NVARCHAR(MAX)", "char": "NCHAR({length})", "char()": "NCHAR(1)", "text": "NVARCHAR(MAX)",
+		"bool": "BIT", "int16": "SMALLINT", "int32": "INT", "int64": "BIGINT", "float32": "REAL", "float64": "FLOAT",
+		"decimal": "DECIMAL({precision},{scale})", "decimal()": "DECIMAL",
+		"date": "DATE", "time": "TIME", "timestamp": "DATETIME2", "uuid": "UNIQUEIDENTIFIER", "json": "NVARCHAR(MAX)",
+		"bytes": "VARBINARY({length})", "bytes()": "VARBINARY(MAX)",
+	},
+	DialectPostgres: {
+		"string": "VARCHAR({length})", "string()": "TEXT", "char": "CHAR({length})", "char()": "CHAR(1)", "text": "TEXT",
+		"bool": "BOOLEAN", "int16": "SMALLINT", "int32": "INTEGER", "int64": "BIGINT", "float32": "REAL", "float64": "DOUBLE PRECISION",
+		"decimal": "NUMERIC({precision},{scale})", "decimal()": "NUMERIC",
+		"date": "DATE", "time": "TIME", "timestamp": "TIMESTAMP", "uuid": "UUID", "json": "JSONB",
+		"bytes": "BYTEA", "bytes()": "BYTEA",
+	},
+	DialectSqlite: {
+		"string": "TEXT", "char": "TEXT", "text": "TEXT",
+		"bool": "INTEGER", "int16": "INTEGER", "int32": "INTEGER", "int64": "INTEGER", "float32": "REAL", "float64": "REAL",
+		"decimal": "NUMERIC", "date": "TEXT", "time": "TEXT", "timestamp": "TEXT", "uuid": "TEXT", "json": "TEXT",
+		"bytes": "BLOB",
+	},
+}
+
+// rawTypeMappers convert the raw types of other databases for the dialects
+// not sharing the same type names.
+var rawTypeMappers = map[string]func(string) string{
+	DialectPostgres: pgType,
+	DialectSqlite:   sqliteType,
+}
+
+var (
+	typeRegexp        = regexp.MustCompile(`^\s*([A-Za-z][A-Za-z0-9_ ]*?)\s*(?:\(([^)]*)\))?\s*$`)
+	placeholderRegexp = regexp.MustCompile(`\s*\([^)]*\{[a-z]+\}[^)]*\)`)
+)
+
+// ParseType parses the data type, it returns an error if it is a logical type
+// with invalid parameters.
+func ParseType(dataType string) (Type, error) {
+	t := Type{Raw: dataType}
+	m := typeRegexp.FindStringSubmatch(dataType)
+	if m == nil {
+		t.Name = strings.TrimSpace(dataType)
+		return t, nil
+	}
+	t.Name = m[1]
+
+	nparam, found := logicalTypes[t.Name]
+	if !found {
+		return t, nil
+	}
+	t.Logical = true
+
+	params := make([]int, 0)
+	if strings.TrimSpace(m[2]) != "" {
+		for _, p := range strings.Split(m[2], ",") {
+			v, err := strconv.Atoi(strings.TrimSpace(p))
+			if err != nil || v <= 0 {
+				return t, tracerr.Errorf("invalid parameter '%s' of the type '%s'", strings.TrimSpace(p), dataType)
+			}
+			params = append(params, v)
+		}
+	}
+	if len(params) > nparam {
+		return t, tracerr.Errorf("type '%s' allows %d parameter(s) only", dataType, nparam)
+	}
+	switch t.Name {
+	case "decimal":
+		if len(params) > 0 {
+			t.Precision = params[0]
+		}
+		if len(params) > 1 {
+			t.Scale = params[1]
+		}
+		if t.Scale > t.Precision {
+			return t, tracerr.Errorf("scale is greater than precision of the type '%s'", dataType)
+		}
+	default:
+		if len(params) > 0 {
+			t.Length = params[0]
+		}
+	}
+	return t, nil
+}
+
+// String returns the normalized data type.
+func (t Type) String() string {
+	if !t.Logical {
+		return t.Raw
+	}
+	switch {
+	case t.Precision > 0:
+		return t.Name + "(" + strconv.Itoa(t.Precision) + "," + strconv.Itoa(t.Scale) + ")"
+	case t.Length > 0:
+		return t.Name + "(" + strconv.Itoa(t.Length) + ")"
+	}
+	return t.Name
+}
+
+// parseColumnType returns the parsed data type, the raw type is returned if
+// the logical type is invalid (reported by Verify).
+func parseColumnType(dataType string) Type {
+	t, err := ParseType(dataType)
+	if err != nil {
+		return Type{Name: t.Name, Raw: dataType}
+	}
+	return t
+}
+
+// Type returns the parsed data type of the column, which is kept by Resolve,
+// the type is parsed if the column is not resolved or its type is changed.
+func (c Column) Type() Type {
+	if c.parsedType != nil && c.parsedType.Raw == c.DataType {
+		return *c.parsedType
+	}
+	return parseColumnType(c.DataType)
+}
+
+// mapping returns the mapping of the logical type name (e.g. string or
+// string()) of the dialect, the mappings override the built-in ones.
+func (m TypeMappings) mapping(dialect string, name string) (string, bool) {
+	for d, mappings := range m {
+		if !strings.EqualFold(d, dialect) {
+			continue
+		}
+		for k, v := range mappings {
+			if strings.EqualFold(k, name) {
+				return v, true
+			}
+		}
+	}
+	mapping, found := defaultTypeMappings[dialect][name]
+	return mapping, found
+}
+
+// MapType returns the data type of the dialect, the logical type is mapped by
+// the type mappings (nil for the built-in ones) of the dialect, the raw type
+// is returned as is unless the dialect converts the raw types (postgres and
+// sqlite).
+func MapType(t Type, dialect string, types TypeMappings) string {
+	if !t.Logical {
+		if mapper, found := rawTypeMappers[dialect]; found {
+			return mapper(t.Raw)
+		}
+		return t.Raw
+	}
+
+	mapping, found := "", false
+	if t.Length == 0 && t.Precision == 0 {
+		mapping, found = types.mapping(dialect, t.Name+"()")
+	}
+	if !found {
+		mapping, found = types.mapping(dialect, t.Name)
+	}
+	if !found {
+		return t.Raw
+	}
+	if t.Length == 0 && t.Precision == 0 {
+		// no parameter, remove the parameter placeholders
+		mapping = placeholderRegexp.ReplaceAllString(mapping, "")
+	}
+	return strings.NewReplacer(
+		"{length}", strconv.Itoa(t.Length),
+		"{precision}", strconv.Itoa(t.Precision),
+		"{scale}", strconv.Itoa(t.Scale),
+	).Replace(mapping)
+}
+
+// Dialects returns the dialects of the built-in type mappings.
+func Dialects() []string {
+	dialects := lo.Keys(defaultTypeMappings)
+	sort.Strings(dialects)
+	return dialects
+}
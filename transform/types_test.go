@@ -0,0 +1,127 @@
+package transform
+
+import (
+	"testing"
+)
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		dataType string
+		want     Type
+		err      bool
+	}{
+		{"string(50)", Type{Name: "string", Length: 50, Logical: true, Raw: "string(50)"}, false},
+		{"decimal(10, 2)", Type{Name: "decimal", Precision: 10, Scale: 2, Logical: true, Raw: "decimal(10, 2)"}, false},
+		{"int64", Type{Name: "int64", Logical: true, Raw: "int64"}, false},
+		{"text", Type{Name: "text", Logical: true, Raw: "text"}, false},
+		// the raw types in upper case are not taken as the logical types
+		{"TEXT", Type{Name: "TEXT", Raw: "TEXT"}, false},
+		{"CHAR(3)", Type{Name: "CHAR", Raw: "CHAR(3)"}, false},
+		{"VARCHAR(50)", Type{Name: "VARCHAR", Raw: "VARCHAR(50)"}, false},
+		{"varchar(50)", Type{Name: "varchar", Raw: "varchar(50)"}, false},
+		{"string(0)", Type{}, true},
+		{"int64(8)", Type{}, true},
+		{"decimal(2,3)", Type{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dataType, func(t *testing.T) {
+			got, err := ParseType(tt.dataType)
+			if (err != nil) != tt.err {
+				t.Fatalf("ParseType(%q) error = %v, want error %v", tt.dataType, err, tt.err)
+			}
+			if !tt.err && got != tt.want {
+				t.Errorf("ParseType(%q) = %+v, want %+v", tt.dataType, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapType(t *testing.T) {
+	types := TypeMappings{"Postgres": {"JSON": "JSON"}, "oracle": {"string": "VARCHAR2({length})"}}
+	tests := []struct {
+		dataType string
+		dialect  string
+		types    TypeMappings
+		want     string
+	}{
+		{"string(50)", DialectMariaDB, nil, "VARCHAR(50)"},
+		{"string", DialectMSSQL, nil, "NVARCHAR(MAX)"},
+		{"decimal(10,2)", DialectPostgres, nil, "NUMERIC(10,2)"},
+		{"decimal", DialectMariaDB, nil, "DECIMAL"},
+		{"text", DialectMariaDB, nil, "LONGTEXT"},
+		{"TEXT", DialectMariaDB, nil, "TEXT"},
+		{"char(3)", DialectMSSQL, nil, "NCHAR(3)"},
+		{"CHAR(3)", DialectMSSQL, nil, "CHAR(3)"},
+		{"DATETIME", DialectPostgres, nil, "TIMESTAMP"},
+		{"json", DialectPostgres, nil, "JSONB"},
+		{"json", DialectPostgres, types, "JSON"},
+		{"uuid", DialectPostgres, types, "UUID"},
+		{"string(20)", "oracle", types, "VARCHAR2(20)"},
+	}
+	for _, tt := range tests {
+		if got := MapType(parseColumnType(tt.dataType), tt.dialect, tt.types); got != tt.want {
+			t.Errorf("MapType(%q, %s) = %s, want %s", tt.dataType, tt.dialect, got, tt.want)
+		}
+	}
+	// the mappings are passed, not kept
+	if got := MapType(parseColumnType("json"), DialectPostgres, nil); got != "JSONB" {
+		t.Errorf("built-in mapping is changed to %s", got)
+	}
+}
+
+func TestLogicalTypeDDL(t *testing.T) {
+	data := readTestYml(t, `schemas:
+  - name: app
+    tables:
+      - name: doc
+        columns:
+          - {na: doc_id, ty: int64, id: Y, nu: Y}
+          - {na: title, ty: string(50), nu: Y}
+          - {na: created_at, ty: timestamp}
+          - {na: note, ty: TEXT}
+`)
+	if findings := Verify(data, LintConfig{}); len(findings) > 0 {
+		t.Errorf("unexpected findings %v", findings)
+	}
+	tests := []struct {
+		dialect string
+		write   func(out string) error
+		want    []string
+	}{
+		{DialectMariaDB, func(out string) error { return WriteTpl(data, "mariadb", out, "", nil) },
+			[]string{"doc_id BIGINT", "title VARCHAR(50) NOT NULL", "created_at DATETIME", "note TEXT"}},
+		{DialectMSSQL, func(out string) error { return WriteTpl(data, "mssql-create", out, "", nil) },
+			[]string{"doc_id BIGINT", "title NVARCHAR(50) NOT NULL", "created_at DATETIME2", "note TEXT"}},
+		{DialectPostgres, func(out string) error { return WritePostgres(data, out, nil) },
+			[]string{"doc_id BIGINT", "title VARCHAR(50) NOT NULL", "created_at TIMESTAMP", "note TEXT"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			assertInOrder(t, readTestOutput(t, "ddl.sql", tt.write), tt.want...)
+		})
+	}
+}
+
+func TestResolveParsedType(t *testing.T) {
+	data := Resolve(readTestYml(t, `domains:
+  money: { ty: "decimal(12,2)" }
+schemas:
+  - name: app
+    tables:
+      - name: doc
+        columns:
+          - { na: price, ty: $money }
+`))
+	column := data.Schemas[0].Tables[0].Columns[0]
+	if column.parsedType == nil || column.parsedType.Precision != 12 {
+		t.Fatalf("parsed type = %v", column.parsedType)
+	}
+	if got := column.Type(); got != *column.parsedType {
+		t.Errorf("Type() = %+v, want %+v", got, *column.parsedType)
+	}
+	// the changed type is parsed again
+	column.DataType = "int64"
+	if got := column.Type(); got.Name != "int64" {
+		t.Errorf("Type() of the changed type = %+v", got)
+	}
+}
@@ -50,6 +50,12 @@ func wildCardMatchs(pattern []string, value string) bool {
 	return false
 }
 
+// matchPattern checks if the name matches any of the comma separated
+// wildcard patterns, an empty pattern matches all names.
+func matchPattern(pattern string, name string) bool {
+	return pattern == "" || wildCardMatchs(strings.Split(pattern, ","), name)
+}
+
 // FilterData filters the data based on the given schema and table patterns.
 //
 // It takes a pointer to a DataDef struct, a schema pattern string, and a table pattern string as parameters.
@@ -62,10 +68,10 @@ func FilterData(data *DataDef, schemaPattern string, tablePattern string) *DataD
 
 	for i := 0; i < len(data.Schemas); i++ {
 		schema := data.Schemas[i]
-		isSchemaMatched := schemaPattern == "" || wildCardMatchs(strings.Split(schemaPattern, ","), schema.Name)
+		isSchemaMatched := matchPattern(schemaPattern, schema.Name)
 		if isSchemaMatched {
 			tables := lo.Filter(schema.Tables, func(t Table, _ int) bool {
-				return tablePattern == "" || wildCardMatchs(strings.Split(tablePattern, ","), t.Name)
+				return matchPattern(tablePattern, t.Name)
 			})
 			if len(tables) > 0 {
 				schema.Tables = tables
@@ -113,47 +119,21 @@ func SearchPathFiles(filename string) ([]string, error) {
 	return nil, tracerr.Errorf("no matching files found")
 }
 
-func Verify(data *DataDef) []string {
-	tables := make(map[string][]Column)
-
-	// convert to map for easy searching
-	for _, schema := range data.Schemas {
-		for _, table := range schema.Tables {
-			tables[table.Name] = table.Columns
-		}
+// writeText writes the text to the out file, or standard output if out is
+// empty or "stdout".
+func writeText(out string, text string) error {
+	if out == "" || out == "stdout" {
+		fmt.Print(text)
+		return nil
 	}
-	tables["fixed"] = data.Fixed
-
-	isFKExist := func(fk string) bool {
-		// fk format: table.field
-		tf := strings.Split(fk, ".")
-		if columns, found := tables[tf[0]]; found {
-			for _, column := range columns {
-				if column.Name == tf[1] {
-					return true
-				}
-			}
-		}
-		return false
+	if err := os.WriteFile(out, []byte(text), 0644); err != nil {
+		return tracerr.Wrap(err)
 	}
+	return nil
+}
 
-	result := make([]string, 0)
-
-	for k, columns := range tables {
-		for i, column := range columns {
-			if column.Name == "" {
-				result = append(result, fmt.Sprintf("[TB: %s] missing column name at line %d", k, i))
-			}
-			if column.DataType == "" {
-				result = append(result, fmt.Sprintf("[TB: %s] missing data type of the column '%s' at line %d", k, column.Name, i))
-			}
-			if column.ForeignKey != "" {
-				// check the foreign key whether exists
-				if !isFKExist(column.ForeignKey) {
-					result = append(result, fmt.Sprintf("[TB: %s] [FK: %s] cannot be found at line %d", k, column.ForeignKey, i))
-				}
-			}
-		}
-	}
-	return result
+// isOutside checks if the relative path (e.g. of filepath.Rel) is outside of
+// its base directory.
+func isOutside(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
 }
@@ -0,0 +1,398 @@
+package transform
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/samber/lo"
+	"github.com/ztrue/tracerr"
+)
+
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// RuleFKCycle is the rule of the foreign key cycles between the tables, which
+// are reported as the warnings since the cycle is broken by SortTables.
+const RuleFKCycle = "fk-cycle"
+
+// Finding is a single problem reported by the validation of a definition.
+type Finding struct {
+	Rule     string   `json:"rule"`
+	Severity string   `json:"severity"`
+	Schema   string   `json:"schema,omitempty"`
+	Table    string   `json:"table,omitempty"`
+	Column   string   `json:"column,omitempty"`
+	Message  string   `json:"message"`
+	Pos      Position `json:"position"`
+}
+
+func (f Finding) String() string {
+	var sb strings.Builder
+	if pos := f.Pos.String(); pos != "" {
+		sb.WriteString(pos + ": ")
+	}
+	sb.WriteString(fmt.Sprintf("[%s] ", strings.ToUpper(f.Severity)))
+	if f.Schema != "" {
+		sb.WriteString(fmt.Sprintf("[SC: %s] ", f.Schema))
+	}
+	if f.Table != "" {
+		sb.WriteString(fmt.Sprintf("[TB: %s] ", f.Table))
+	}
+	if f.Column != "" {
+		sb.WriteString(fmt.Sprintf("[CO: %s] ", f.Column))
+	}
+	sb.WriteString(f.Message)
+	return sb.String()
+}
+
+// Verify checks the structure of the definition (see VerifyStructure) and the
+// lint rules of the configuration on the resolved definition, see Resolve.
+// The lint findings suppressed by the comments in the yaml files are excluded.
+func Verify(data *DataDef, cfg LintConfig) []Finding {
+	return append(VerifyStructure(data), suppress(lint(Resolve(data), cfg))...)
+}
+
+// VerifyStructure checks the structure of the definition without the lint
+// rules and returns the findings in the order of the schemas, tables and
+// columns. An empty result means the definition can be converted. The
+// domains and mixins are checked first, the other rules are checked on the
+// resolved definition.
+func VerifyStructure(data *DataDef) []Finding {
+	result := verifyReferences(data)
+	data = Resolve(data)
+
+	tables := make(map[string][]Column)
+
+	// convert to map for easy searching
+	for _, schema := range data.Schemas {
+		for _, table := range schema.Tables {
+			tables[table.Name] = table.Columns
+		}
+	}
+	tables["fixed"] = data.Fixed
+
+	isFKExist := func(fk string) bool {
+		// fk format: table.field
+		tf := strings.Split(fk, ".")
+		if len(tf) != 2 {
+			return false
+		}
+		if columns, found := tables[tf[0]]; found {
+			for _, column := range columns {
+				if column.Name == tf[1] {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	verifyColumns := func(schema string, table string, columns []Column) {
+		for _, column := range columns {
+			if column.Name == "" {
+				result = append(result, Finding{Rule: "column-name", Severity: SeverityError, Schema: schema, Table: table, Pos: column.Pos,
+					Message: "missing column name"})
+			}
+			if column.DataType == "" {
+				result = append(result, Finding{Rule: "column-type", Severity: SeverityError, Schema: schema, Table: table, Column: column.Name, Pos: column.Pos,
+					Message: fmt.Sprintf("missing data type of the column '%s'", column.Name)})
+			}
+			if column.DataType != "" {
+				if _, err := ParseType(column.DataType); err != nil {
+					result = append(result, Finding{Rule: "column-type", Severity: SeverityError, Schema: schema, Table: table, Column: column.Name, Pos: column.Pos,
+						Message: tracerr.Unwrap(err).Error()})
+				}
+			}
+			if dup := lo.FindDuplicates(column.Enum.Values()); len(dup) > 0 {
+				result = append(result, Finding{Rule: "check", Severity: SeverityError, Schema: schema, Table: table, Column: column.Name, Pos: column.Pos,
+					Message: fmt.Sprintf("duplicate enum value(s) %s", strings.Join(dup, ", "))})
+			}
+			if column.ForeignKey != "" {
+				// check the foreign key whether exists
+				if !isFKExist(column.ForeignKey) {
+					result = append(result, Finding{Rule: "foreign-key", Severity: SeverityError, Schema: schema, Table: table, Column: column.Name, Pos: column.Pos,
+						Message: fmt.Sprintf("[FK: %s] cannot be found", column.ForeignKey)})
+				}
+			}
+		}
+	}
+
+	verifyPrimaryKey := func(schema string, table Table) {
+		if len(table.PrimaryKey) == 0 {
+			return
+		}
+		columns := tableColumns(data, table)
+		seen := make(map[string]bool)
+		for _, name := range table.PrimaryKey {
+			if seen[name] {
+				result = append(result, Finding{Rule: "primary-key", Severity: SeverityError, Schema: schema, Table: table.Name, Column: name, Pos: table.Pos,
+					Message: fmt.Sprintf("duplicate column '%s' in the primary key", name)})
+				continue
+			}
+			seen[name] = true
+			if !lo.ContainsBy(columns, func(c Column) bool { return c.Name == name }) {
+				result = append(result, Finding{Rule: "primary-key", Severity: SeverityError, Schema: schema, Table: table.Name, Column: name, Pos: table.Pos,
+					Message: fmt.Sprintf("column '%s' of the primary key cannot be found", name)})
+			}
+		}
+		for _, column := range columns {
+			if isYes(column.Identity) && !seen[column.Name] {
+				result = append(result, Finding{Rule: "primary-key", Severity: SeverityWarning, Schema: schema, Table: table.Name, Column: column.Name, Pos: column.Pos,
+					Message: fmt.Sprintf("identity column '%s' is not in the primary key", column.Name)})
+			}
+		}
+	}
+
+	verifyForeignKeys := func(schema string, table Table) {
+		columns := tableColumns(data, table)
+		for i, fk := range table.ForeignKeys {
+			fk := data.resolveForeignKey(fk)
+			name := lo.Ternary(fk.Name != "", fk.Name, fmt.Sprintf("#%d", i+1))
+			report := func(severity string, format string, args ...any) {
+				result = append(result, Finding{Rule: "foreign-key", Severity: severity, Schema: schema, Table: table.Name,
+					Column: strings.Join(fk.Columns, ", "), Pos: fk.Pos, Message: fmt.Sprintf("[FK: %s] ", name) + fmt.Sprintf(format, args...)})
+			}
+			if len(fk.Columns) == 0 {
+				report(SeverityError, "missing columns")
+				continue
+			}
+			for _, action := range []string{fk.OnDelete, fk.OnUpdate} {
+				if action != "" && !lo.Contains(fkActions, strings.ToUpper(action)) {
+					report(SeverityError, "invalid action '%s', expect one of %s", action, strings.Join(fkActions, ", "))
+				}
+			}
+			rtable, found := findTable(data, fk.RefTable)
+			if !found {
+				report(SeverityError, "referenced table '%s' cannot be found", fk.RefTable)
+				continue
+			}
+			if len(fk.RefColumns) != len(fk.Columns) {
+				report(SeverityError, "%d column(s) reference %d column(s) of the table '%s'", len(fk.Columns), len(fk.RefColumns), fk.RefTable)
+				continue
+			}
+			// the types are checked by the lint rule fk-type
+			rcolumns := tableColumns(data, rtable)
+			for j := range fk.Columns {
+				if !lo.ContainsBy(columns, func(c Column) bool { return c.Name == fk.Columns[j] }) {
+					report(SeverityError, "column '%s' cannot be found", fk.Columns[j])
+				}
+				if !lo.ContainsBy(rcolumns, func(c Column) bool { return c.Name == fk.RefColumns[j] }) {
+					report(SeverityError, "referenced column '%s.%s' cannot be found", fk.RefTable, fk.RefColumns[j])
+				}
+			}
+		}
+	}
+
+	indexNames := make(map[string]bool)
+	verifyIndexes := func(schema string, table Table) {
+		columns := tableColumns(data, table)
+		exists := func(name string) bool {
+			return lo.ContainsBy(columns, func(c Column) bool { return c.Name == name })
+		}
+		for i, ix := range table.Indexes {
+			name := lo.Ternary(ix.Name != "", ix.Name, fmt.Sprintf("#%d", i+1))
+			report := func(format string, args ...any) {
+				result = append(result, Finding{Rule: "index", Severity: SeverityError, Schema: schema, Table: table.Name,
+					Column: strings.Join(ix.ColumnNames(), ", "), Pos: ix.Pos, Message: fmt.Sprintf("[IX: %s] ", name) + fmt.Sprintf(format, args...)})
+			}
+			if len(ix.Columns) == 0 {
+				report("missing columns")
+			}
+			if ix.Name != "" {
+				if indexNames[ix.Name] {
+					report("duplicate index name")
+				}
+				indexNames[ix.Name] = true
+			}
+			for _, column := range ix.Columns {
+				cname, order := splitIndexColumn(column)
+				if !exists(cname) {
+					report("column '%s' cannot be found", cname)
+				}
+				if order != "" && order != "ASC" && order != "DESC" {
+					report("invalid sort order '%s' of the column '%s', expect ASC or DESC", order, cname)
+				}
+			}
+			for _, cname := range ix.Include {
+				if !exists(cname) {
+					report("included column '%s' cannot be found", cname)
+				}
+			}
+		}
+	}
+
+	verifyChecks := func(schema string, table Table) {
+		for i, check := range table.Checks {
+			if strings.TrimSpace(check.Expr) == "" {
+				result = append(result, Finding{Rule: "check", Severity: SeverityError, Schema: schema, Table: table.Name, Pos: check.Pos,
+					Message: fmt.Sprintf("[CK: %s] missing expression", lo.Ternary(check.Name != "", check.Name, fmt.Sprintf("#%d", i+1)))})
+			}
+		}
+		names := lo.Map(data.Checks(table), func(c Check, _ int) string { return c.Name })
+		if dup := lo.FindDuplicates(names); len(dup) > 0 {
+			result = append(result, Finding{Rule: "check", Severity: SeverityError, Schema: schema, Table: table.Name, Pos: table.Pos,
+				Message: fmt.Sprintf("duplicate check name(s) %s", strings.Join(dup, ", "))})
+		}
+	}
+
+	verifyColumns("", "fixed", data.Fixed)
+	// the table names are unique across the schemas and the files
+	seen := make(map[string]Position)
+	for _, schema := range data.Schemas {
+		for _, table := range schema.Tables {
+			if pos, found := seen[table.Name]; found {
+				result = append(result, Finding{Rule: "table", Severity: SeverityError, Schema: schema.Name, Table: table.Name, Pos: table.Pos,
+					Message: fmt.Sprintf("duplicate table '%s', first defined at %s", table.Name, pos.String())})
+			} else {
+				seen[table.Name] = table.Pos
+			}
+			verifyPrimaryKey(schema.Name, table)
+			verifyColumns(schema.Name, table.Name, table.Columns)
+			verifyForeignKeys(schema.Name, table)
+			verifyIndexes(schema.Name, table)
+			verifyChecks(schema.Name, table)
+		}
+	}
+
+	// the cycles are reported at the foreign key of the first table to the next one
+	refs, _ := tableGraph(data)
+	for _, cycle := range TableCycles(data) {
+		ref, _ := lo.Find(refs, func(ref TableRef) bool { return ref.Table.Name == cycle[0] })
+		fk, _ := lo.Find(data.ForeignKeys(ref.Table), func(fk ForeignKey) bool { return fk.RefTable == cycle[1] })
+		result = append(result, Finding{Rule: RuleFKCycle, Severity: SeverityWarning, Schema: ref.Schema, Table: ref.Table.Name,
+			Column: strings.Join(fk.Columns, ", "), Pos: fk.Pos,
+			Message: fmt.Sprintf("foreign key cycle %s, the tables cannot be created with the foreign keys or dropped in order", strings.Join(cycle, " -> "))})
+	}
+	return result
+}
+
+// verifyReferences checks the domains and mixins referred by the columns
+// and tables are defined.
+func verifyReferences(data *DataDef) []Finding {
+	result := make([]Finding, 0)
+
+	verifyDomains := func(schema string, table string, columns []Column) {
+		for _, column := range columns {
+			if name := domainName(column.DataType); name != "" {
+				if _, found := data.Domains[name]; !found {
+					result = append(result, Finding{Rule: "domain", Severity: SeverityError, Schema: schema, Table: table, Column: column.Name, Pos: column.Pos,
+						Message: fmt.Sprintf("domain '%s' cannot be found", name)})
+				}
+			}
+		}
+	}
+
+	names := lo.Keys(data.Domains)
+	sort.Strings(names)
+	for _, name := range names {
+		if domain := data.Domains[name]; domainName(domain.DataType) != "" {
+			result = append(result, Finding{Rule: "domain", Severity: SeverityError, Pos: domain.Pos,
+				Message: fmt.Sprintf("domain '%s' cannot refer to other domain", name)})
+		}
+	}
+	mixins := make(map[string]bool)
+	for _, mixin := range data.Mixins {
+		if mixins[mixin.Name] {
+			result = append(result, Finding{Rule: "mixin", Severity: SeverityError, Pos: mixin.Pos,
+				Message: fmt.Sprintf("duplicate mixin '%s'", mixin.Name)})
+		}
+		mixins[mixin.Name] = true
+		verifyDomains("", "", mixin.Columns)
+	}
+	verifyDomains("", "", data.Fixed)
+	for _, schema := range data.Schemas {
+		for _, table := range schema.Tables {
+			for _, name := range table.Use {
+				if !mixins[name] {
+					result = append(result, Finding{Rule: "mixin", Severity: SeverityError, Schema: schema.Name, Table: table.Name, Pos: table.Pos,
+						Message: fmt.Sprintf("mixin '%s' cannot be found", name)})
+				}
+			}
+			verifyDomains(schema.Name, table.Name, table.Columns)
+		}
+	}
+	return result
+}
+
+// WriteFindings writes the findings to w in the given format (text, json,
+// junit or sarif).
+func WriteFindings(w io.Writer, findings []Finding, format string) error {
+	switch strings.ToLower(format) {
+	case "", "text":
+		for _, f := range findings {
+			if _, err := fmt.Fprintln(w, f.String()); err != nil {
+				return tracerr.Wrap(err)
+			}
+		}
+		return nil
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(findings); err != nil {
+			return tracerr.Wrap(err)
+		}
+		return nil
+	case "junit":
+		return writeJUnit(w, findings)
+	case "sarif":
+		return writeSarif(w, findings)
+	}
+	return tracerr.Errorf("unknown format '%s', supported formats: text, json, junit, sarif", format)
+}
+
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Type    string `xml:"type,attr"`
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, findings []Finding) error {
+	suite := junitSuite{Name: "dst verify", Tests: len(findings), Failures: len(findings)}
+	for _, f := range findings {
+		suite.Cases = append(suite.Cases, junitCase{
+			Name:      strings.Join(lo.Compact([]string{f.Table, f.Column, f.Rule}), "."),
+			ClassName: lo.Ternary(f.Schema != "", f.Schema, "dst"),
+			Failure:   &junitFailure{Type: f.Severity, Message: f.Message, Text: f.String()},
+		})
+	}
+	if len(findings) == 0 {
+		// a passed test case, otherwise some CI tools treat an empty suite as failed
+		suite.Tests = 1
+		suite.Cases = append(suite.Cases, junitCase{Name: "verify", ClassName: "dst"})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return tracerr.Wrap(err)
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(junitSuites{Suites: []junitSuite{suite}}); err != nil {
+		return tracerr.Wrap(err)
+	}
+	_, err := io.WriteString(w, "\n")
+	return tracerr.Wrap(err)
+}
@@ -0,0 +1,121 @@
+package transform
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+func TestVerifyPositions(t *testing.T) {
+	data := readTestYml(t, `fixed:
+  - { na: created, ty: "" }
+schemas:
+  - name: app
+    tables:
+      - name: doc
+        columns:
+          - { na: doc_id, ty: INT, id: Y, nu: Y }
+          - { na: title }
+          - { na: tag_id, ty: INT, fk: tag.tag_id }
+        indexes:
+          - { name: ix_doc_ver, columns: [ver] }
+`)
+	// the positions are the lines and columns in the file, not the indexes of
+	// the columns in the table
+	dir := filepath.Dir(data.Schemas[0].Tables[0].Pos.File)
+	got := strings.Join(lo.Map(VerifyStructure(data), func(f Finding, _ int) string {
+		return strings.TrimPrefix(f.String(), dir+string(filepath.Separator))
+	}), "\n")
+	want := strings.Join([]string{
+		"s.yml:2:5: [ERROR] [TB: fixed] [CO: created] missing data type of the column 'created'",
+		"s.yml:9:13: [ERROR] [SC: app] [TB: doc] [CO: title] missing data type of the column 'title'",
+		"s.yml:10:13: [ERROR] [SC: app] [TB: doc] [CO: tag_id] [FK: tag.tag_id] cannot be found",
+		"s.yml:12:13: [ERROR] [SC: app] [TB: doc] [CO: ver] [IX: ix_doc_ver] column 'ver' cannot be found",
+	}, "\n")
+	if got != want {
+		t.Errorf("findings =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestVerifyPrimaryKey(t *testing.T) {
+	tests := []struct {
+		name string
+		pk   string
+		want string
+	}{
+		{"composite", "[doc_id, tag_id]", "identity column 'seq' is not in the primary key"},
+		{"with identity", "[doc_id, tag_id, seq]", ""},
+		{"fixed column", "[doc_id, created]", "identity column 'seq' is not in the primary key"},
+		{"duplicate", "[doc_id, doc_id, seq]", "duplicate column 'doc_id' in the primary key"},
+		{"missing", "[doc_id, lang, seq]", "column 'lang' of the primary key cannot be found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := readTestYml(t, `fixed:
+  - { na: created, ty: DATETIME, nu: Y }
+schemas:
+  - name: app
+    tables:
+      - name: doc_tag
+        primary_key: `+tt.pk+`
+        columns:
+          - { na: doc_id, ty: INT, nu: Y }
+          - { na: tag_id, ty: INT, nu: Y }
+          - { na: seq, ty: INT, id: Y, nu: Y }
+`)
+			got := strings.Join(lo.Map(VerifyStructure(data), func(f Finding, _ int) string { return f.Message }), "\n")
+			if got != tt.want {
+				t.Errorf("findings =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyForeignKeys(t *testing.T) {
+	tests := []struct {
+		name string
+		fk   string
+		want string
+	}{
+		{"composite", "{ name: fk_tag, columns: [tag_code, tag_lang], ref_table: tag, ref_columns: [code, lang], on_delete: cascade }", ""},
+		{"count", "{ name: fk_tag, columns: [tag_code, tag_lang], ref_table: tag, ref_columns: [code] }",
+			"foreign-key error [FK: fk_tag] 2 column(s) reference 1 column(s) of the table 'tag'"},
+		{"table", "{ columns: [tag_code], ref_table: label, ref_columns: [code] }",
+			"foreign-key error [FK: #1] referenced table 'label' cannot be found"},
+		{"columns", "{ columns: [tag_id, tag_lang], ref_table: tag, ref_columns: [code, name] }",
+			"foreign-key error [FK: #1] column 'tag_id' cannot be found\nforeign-key error [FK: #1] referenced column 'tag.name' cannot be found"},
+		{"action", "{ columns: [tag_code, tag_lang], ref_table: tag, ref_columns: [code, lang], on_delete: DROP }",
+			"foreign-key error [FK: #1] invalid action 'DROP', expect one of CASCADE, SET NULL, SET DEFAULT, RESTRICT, NO ACTION"},
+		// the types are checked by the lint rule
+		{"type", "{ columns: [tag_lang, tag_code], ref_table: tag, ref_columns: [code, lang] }",
+			"fk-type error [FK: #1] type 'CHAR(2)' of the column 'tag_lang' does not match the type 'VARCHAR(10)' of 'tag.code'\n" +
+				"fk-type error [FK: #1] type 'VARCHAR(10)' of the column 'tag_code' does not match the type 'CHAR(2)' of 'tag.lang'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := readTestYml(t, `schemas:
+  - name: app
+    tables:
+      - name: tag
+        primary_key: [code, lang]
+        columns:
+          - { na: code, ty: VARCHAR(10), nu: Y }
+          - { na: lang, ty: CHAR(2), nu: Y }
+      - name: doc_tag
+        columns:
+          - { na: doc_id, ty: INT, nu: Y }
+          - { na: tag_code, ty: VARCHAR(10), nu: Y }
+          - { na: tag_lang, ty: CHAR(2), nu: Y }
+        foreign_keys:
+          - `+tt.fk+`
+`)
+			findings := lo.Filter(Verify(data, LintConfig{}), func(f Finding, _ int) bool { return f.Severity == SeverityError })
+			got := strings.Join(lo.Map(findings, func(f Finding, _ int) string { return f.Rule + " " + f.Severity + " " + f.Message }), "\n")
+			if got != tt.want {
+				t.Errorf("findings =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package transform
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 
 	"github.com/samber/lo"
@@ -9,52 +10,323 @@ import (
 	"github.com/ztrue/tracerr"
 )
 
+func init() {
+	Register(Format{
+		Name:    "excel",
+		Aliases: []string{"e", "xlsx"},
+		Usage:   "data dictionary in Excel",
+		Exts:    []string{".xlsx"},
+		Options: []string{OptSimple},
+		Reader:  ReaderFunc(func(file string, _ Options) (*DataDef, error) { return ReadXlsx(file) }),
+		Writer:  WriterFunc(func(data *DataDef, out string, opts Options) error { return WriteXlsx(data, out, opts.Simple) }),
+	})
+}
+
+// dictColumn is a column of the data dictionary sheet, the first sheet column
+// is reserved for the table name.
+type dictColumn struct {
+	heading string
+	width   float64
+	get     func(c *Column) string
+	set     func(c *Column, v string)
+}
+
 const (
-	CEmpty      = 0
-	CName       = 1
-	CTitle      = 2
-	CDataType   = 3
-	CIdentity   = 4
-	CNotNull    = 5
-	CValue      = 6
-	CForeignKey = 7
-	CDesc       = 8
+	// primaryKeyHeading is the heading of the column containing the primary
+	// key columns in the table row, e.g. "doc_id, tag_id"
+	primaryKeyHeading = "Primary Key"
+	// fixedHeading is the heading of the column marking the fixed columns
+	fixedHeading = "Fixed"
 )
 
+var dictColumns = []dictColumn{
+	{"Column Name", 20, func(c *Column) string { return c.Name }, func(c *Column, v string) { c.Name = v }},
+	{"Title", 20, func(c *Column) string { return c.Title }, func(c *Column, v string) { c.Title = v }},
+	{"Data Type", 15, func(c *Column) string { return c.DataType }, func(c *Column, v string) { c.DataType = v }},
+	{"Identity", 8, func(c *Column) string { return c.Identity }, func(c *Column, v string) { c.Identity = v }},
+	{"Not Null", 8, func(c *Column) string { return c.NotNull }, func(c *Column, v string) { c.NotNull = v }},
+	{"Unique", 8, func(c *Column) string { return c.Unique }, func(c *Column, v string) { c.Unique = v }},
+	{"Index", 8, func(c *Column) string { return c.Index }, func(c *Column, v string) { c.Index = v }},
+	{"Default", 10, func(c *Column) string { return c.Value }, func(c *Column, v string) { c.Value = v }},
+	{"Foreign Key", 25, func(c *Column) string { return c.ForeignKey }, func(c *Column, v string) { c.ForeignKey = v }},
+	{"Cardinality", 10, func(c *Column) string { return c.Cardinality }, func(c *Column, v string) { c.Cardinality = v }},
+	{"Values", 25, func(c *Column) string { return formatDictEnum(c.Enum) }, func(c *Column, v string) { c.Enum = parseDictEnum(v) }},
+	{"Description", 50, func(c *Column) string { return c.Desc }, func(c *Column, v string) { c.Desc = v }},
+}
+
+// dictForeignKeyRegexp matches the table foreign key in the data dictionary,
+// e.g. fk_name: doc_id, tag_id -> doc_tag(doc_id, tag_id) ON DELETE CASCADE
+var dictForeignKeyRegexp = regexp.MustCompile(`(?i)^\s*(?:([^:]+):)?\s*([^>]+?)\s*->\s*([^(\s]+)\s*(?:\(([^)]*)\))?\s*(?:ON\s+DELETE\s+(.+?))?\s*(?:ON\s+UPDATE\s+(.+?))?\s*$`)
+
+// formatDictForeignKey returns the foreign key of the table in the data
+// dictionary format, see dictForeignKeyRegexp.
+func formatDictForeignKey(fk ForeignKey) string {
+	var sb strings.Builder
+	if fk.Name != "" {
+		sb.WriteString(fk.Name + ": ")
+	}
+	sb.WriteString(strings.Join(fk.Columns, ", ") + " -> " + fk.RefTable)
+	if len(fk.RefColumns) > 0 {
+		sb.WriteString("(" + strings.Join(fk.RefColumns, ", ") + ")")
+	}
+	if fk.OnDelete != "" {
+		sb.WriteString(" ON DELETE " + fk.OnDelete)
+	}
+	if fk.OnUpdate != "" {
+		sb.WriteString(" ON UPDATE " + fk.OnUpdate)
+	}
+	return sb.String()
+}
+
+// parseDictForeignKey parses the foreign key of the table in the data
+// dictionary format, see dictForeignKeyRegexp.
+func parseDictForeignKey(text string) (ForeignKey, error) {
+	m := dictForeignKeyRegexp.FindStringSubmatch(text)
+	if m == nil {
+		return ForeignKey{}, tracerr.Errorf("invalid foreign key '%s'", text)
+	}
+	fk := ForeignKey{Name: strings.TrimSpace(m[1]), Columns: splitNames(m[2]), RefTable: m[3], OnDelete: m[5], OnUpdate: m[6]}
+	if strings.TrimSpace(m[4]) != "" {
+		fk.RefColumns = splitNames(m[4])
+	}
+	return fk, nil
+}
+
+// dictIndexRegexp matches the table index in the data dictionary, e.g.
+// idx_name: UNIQUE tag_id, created_at DESC INCLUDE (title) WHERE deleted = 0
+var dictIndexRegexp = regexp.MustCompile(`(?i)^\s*(?:([A-Za-z0-9_$]+):)?\s*(UNIQUE\s+)?(.+?)(?:\s+INCLUDE\s*\(([^)]*)\))?(?:\s+WHERE\s+(.+?))?\s*$`)
+
+// formatDictIndex returns the index of the table in the data dictionary
+// format, see dictIndexRegexp.
+func formatDictIndex(ix Index) string {
+	var sb strings.Builder
+	if ix.Name != "" {
+		sb.WriteString(ix.Name + ": ")
+	}
+	if ix.Unique {
+		sb.WriteString("UNIQUE ")
+	}
+	sb.WriteString(strings.Join(ix.Columns, ", "))
+	if len(ix.Include) > 0 {
+		sb.WriteString(" INCLUDE (" + strings.Join(ix.Include, ", ") + ")")
+	}
+	if ix.Where != "" {
+		sb.WriteString(" WHERE " + ix.Where)
+	}
+	return sb.String()
+}
+
+// parseDictIndex parses the index of the table in the data dictionary format,
+// see dictIndexRegexp.
+func parseDictIndex(text string) (Index, error) {
+	m := dictIndexRegexp.FindStringSubmatch(text)
+	if m == nil {
+		return Index{}, tracerr.Errorf("invalid index '%s'", text)
+	}
+	ix := Index{Name: m[1], Unique: m[2] != "", Columns: splitNames(m[3]), Where: m[5]}
+	if strings.TrimSpace(m[4]) != "" {
+		ix.Include = splitNames(m[4])
+	}
+	return ix, nil
+}
+
+// dictCheckRegexp matches the check of the table in the data dictionary, the
+// name is optional, e.g. ck_doc_ver: ver >= 0
+var dictCheckRegexp = regexp.MustCompile(`^\s*(?:([A-Za-z0-9_$]+):\s)?\s*(.+?)\s*$`)
+
+// formatDictCheck returns the check of the table in the data dictionary
+// format, see dictCheckRegexp.
+func formatDictCheck(check Check) string {
+	if check.Name == "" {
+		return check.Expr
+	}
+	return check.Name + ": " + check.Expr
+}
+
+// parseDictCheck parses the check of the table in the data dictionary format,
+// see dictCheckRegexp.
+func parseDictCheck(text string) Check {
+	m := dictCheckRegexp.FindStringSubmatch(text)
+	if m == nil {
+		return Check{Expr: strings.TrimSpace(text)}
+	}
+	return Check{Name: m[1], Expr: m[2]}
+}
+
+// formatDictEnum returns the enum values of the column, one per line with the
+// optional label, e.g. COM: common
+func formatDictEnum(enum Enum) string {
+	return strings.Join(lo.Map(enum, func(v EnumValue, _ int) string {
+		if v.Label == "" {
+			return v.Value
+		}
+		return v.Value + ": " + v.Label
+	}), "\n")
+}
+
+// parseDictEnum parses the enum values of the column, see formatDictEnum.
+func parseDictEnum(text string) Enum {
+	var enum Enum
+	for _, line := range strings.Split(text, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		value, label, _ := strings.Cut(line, ": ")
+		enum = append(enum, EnumValue{Value: strings.TrimSpace(value), Label: strings.TrimSpace(label)})
+	}
+	return enum
+}
+
+// splitNames splits the comma separated names.
+func splitNames(text string) []string {
+	return lo.Map(strings.Split(text, ","), func(name string, _ int) string { return strings.TrimSpace(name) })
+}
+
+// dictCell returns the cell name of the data dictionary, col 0 is the table
+// name column and col 1 is the first column of dictColumns.
+func dictCell(col int, row int) string {
+	cell, _ := excelize.CoordinatesToCellName(col+1, row)
+	return cell
+}
+
+// ReadXlsx reads the data dictionary created by WriteXlsx, each sheet is a
+// schema and each table starts with a row containing the table name in the
+// first column. The columns are located by the headings, the rows marked as
+// fixed are restored to the fixed columns instead of the table columns.
 func ReadXlsx(infile string) (*DataDef, error) {
 	excel, err := excelize.OpenFile(infile)
 	if err != nil {
 		return nil, tracerr.Wrap(err)
 	}
+	defer excel.Close()
 
 	var data DataDef
+	fixedFound := false
 
 	sheets := excel.GetSheetList()
 	for _, sheet := range sheets {
 		rows, err := excel.GetRows(sheet)
 		if err != nil {
-			tracerr.Wrap(err)
+			return nil, tracerr.Wrap(err)
+		}
+		if len(rows) == 0 {
+			continue
+		}
+
+		// locate the columns by the heading row
+		setters := make(map[int]func(c *Column, v string))
+		titleIndex, descIndex, pkIndex, fkIndex, ixIndex, valuesIndex, fixedIndex := -1, -1, -1, -1, -1, -1, -1
+		for idx, heading := range rows[0] {
+			heading = strings.TrimSpace(heading)
+			if dc, found := lo.Find(dictColumns, func(dc dictColumn) bool { return strings.EqualFold(dc.heading, heading) }); found {
+				setters[idx] = dc.set
+			}
+			switch {
+			case strings.EqualFold(heading, "Title"):
+				titleIndex = idx
+			case strings.EqualFold(heading, "Description"):
+				descIndex = idx
+			case strings.EqualFold(heading, primaryKeyHeading):
+				pkIndex = idx
+			case strings.EqualFold(heading, "Foreign Key"):
+				fkIndex = idx
+			case strings.EqualFold(heading, "Index"):
+				ixIndex = idx
+			case strings.EqualFold(heading, "Values"):
+				valuesIndex = idx
+			case strings.EqualFold(heading, fixedHeading):
+				fixedIndex = idx
+			}
+		}
+		cellOf := func(row []string, idx int) string {
+			if idx < 0 || idx >= len(row) {
+				return ""
+			}
+			return strings.TrimSpace(row[idx])
 		}
 
-		schema := &Schema{Name: sheet}
+		schema := &Schema{Name: sheet, Pos: Position{File: infile}}
 
 		var table *Table
+		var fixed []Column
+		// appendTable appends the last table instance, the fixed columns are
+		// repeated in each table, keep the first one only. The table without
+		// the fixed columns excludes them.
+		appendTable := func() {
+			if table == nil {
+				return
+			}
+			table.ExcludeFixed = len(fixed) == 0
+			schema.Tables = append(schema.Tables, *table)
+			if !fixedFound && len(fixed) > 0 {
+				data.Fixed = fixed
+				fixedFound = true
+			}
+			fixed = nil
+		}
+
 		for rowIndex, row := range rows {
 			if rowIndex == 0 {
 				// skip the heading row
 				continue
 			}
+			if lo.EveryBy(row, func(cell string) bool { return strings.TrimSpace(cell) == "" }) {
+				// skip the empty row
+				continue
+			}
 
-			if len(row) > 0 && row[0] != "" {
-				// table title (first column contains table name and descrition only)
+			if row[0] != "" {
+				// table row (first column contains table name, title and description are in their own columns)
+				appendTable()
+				table = &Table{Pos: Position{File: infile, Line: rowIndex + 1, Col: 1}}
 
-				if table != nil {
-					// append last table instance
-					schema.Tables = append(schema.Tables, *table)
+				if pk := cellOf(row, pkIndex); pk != "" {
+					table.PrimaryKey = splitNames(pk)
+				}
+				if fks := cellOf(row, fkIndex); fks != "" {
+					for _, line := range strings.Split(fks, "\n") {
+						if strings.TrimSpace(line) == "" {
+							continue
+						}
+						fk, err := parseDictForeignKey(line)
+						if err != nil {
+							return nil, tracerr.Errorf("%s: %s in sheet '%s' row %d", infile, err.Error(), sheet, rowIndex+1)
+						}
+						fk.Pos = table.Pos
+						table.ForeignKeys = append(table.ForeignKeys, fk)
+					}
+				}
+				if ixs := cellOf(row, ixIndex); ixs != "" {
+					for _, line := range strings.Split(ixs, "\n") {
+						if strings.TrimSpace(line) == "" {
+							continue
+						}
+						ix, err := parseDictIndex(line)
+						if err != nil {
+							return nil, tracerr.Errorf("%s: %s in sheet '%s' row %d", infile, err.Error(), sheet, rowIndex+1)
+						}
+						ix.Pos = table.Pos
+						table.Indexes = append(table.Indexes, ix)
+					}
+				}
+				if checks := cellOf(row, valuesIndex); checks != "" {
+					for _, line := range strings.Split(checks, "\n") {
+						if strings.TrimSpace(line) == "" {
+							continue
+						}
+						check := parseDictCheck(line)
+						check.Pos = table.Pos
+						table.Checks = append(table.Checks, check)
+					}
 				}
-				table = &Table{}
 
-				tableText := row[0]
+				tableText := strings.TrimSpace(row[0])
+				title, desc := cellOf(row, titleIndex), cellOf(row, descIndex)
+				if title != "" || desc != "" {
+					table.Name, table.Title, table.Desc = tableText, title, desc
+					continue
+				}
+				// the table name, title and description in the first column (older format)
 				parts := strings.Split(tableText, " - ")
 				switch len(parts) {
 				case 0:
@@ -67,45 +339,38 @@ func ReadXlsx(infile string) (*DataDef, error) {
 				default:
 					table.Name = strings.TrimSpace(parts[0])
 					table.Title = strings.TrimSpace(parts[1])
-					table.Desc = strings.TrimSpace(parts[2])
+					table.Desc = strings.TrimSpace(strings.Join(parts[2:], " - "))
 				}
 			} else {
-				incol := Column{}
+				if table == nil {
+					return nil, tracerr.Errorf("%s: column found before the table name in sheet '%s' row %d", infile, sheet, rowIndex+1)
+				}
+				incol := Column{Pos: Position{File: infile, Line: rowIndex + 1, Col: 2}}
 				for idx, cell := range row {
-					cell = strings.TrimSpace(cell)
-					if idx == CName {
-						incol.Name = cell
-					}
-					if idx == CTitle {
-						incol.Title = cell
-					}
-					if idx == CDataType {
-						incol.DataType = cell
-					}
-					if idx == CIdentity {
-						incol.Identity = cell
-					}
-					if idx == CNotNull {
-						incol.NotNull = cell
-					}
-					if idx == CValue {
-						incol.Value = cell
-					}
-					if idx == CForeignKey {
-						incol.ForeignKey = cell
-					}
-					if idx == CDesc {
-						incol.Desc = cell
+					if set, found := setters[idx]; found {
+						set(&incol, strings.TrimSpace(cell))
 					}
 				}
-				table.OutColumns = append(table.OutColumns, OutColumn{Value: incol})
+				if strings.EqualFold(cellOf(row, fixedIndex), "Y") {
+					fixed = append(fixed, incol)
+					continue
+				}
+				table.Columns = append(table.Columns, incol)
 			}
 		}
 		// last table
-		schema.Tables = append(schema.Tables, *table)
+		appendTable()
 		data.Schemas = append(data.Schemas, *schema)
 	}
-	return &data, err
+	if !fixedFound {
+		// no fixed columns, no table excludes them
+		for i := range data.Schemas {
+			for j := range data.Schemas[i].Tables {
+				data.Schemas[i].Tables[j].ExcludeFixed = false
+			}
+		}
+	}
+	return &data, nil
 }
 
 func WriteXlsx(data *DataDef, out string, simple bool) error {
@@ -157,12 +422,13 @@ func writeSimpleDataDict(data *DataDef, out string) error {
 			// find PK and all FK of the table
 			keyData := ""
 			keyDataRow := 0
+			pk := table.PrimaryKeyColumns(nil)
 			for k, column := range table.Columns {
-				if strings.ToUpper(column.Identity) == "Y" || lo.IsNotEmpty(column.ForeignKey) {
+				if lo.Contains(pk, column.Name) || lo.IsNotEmpty(column.ForeignKey) {
 					if k != 0 {
 						keyData += "\n"
 					}
-					if strings.ToUpper(column.Identity) == "Y" {
+					if lo.Contains(pk, column.Name) {
 						keyData += fmt.Sprintf("%s (%s)", column.Name, "PK")
 						keyDataRow += 1
 					} else if lo.IsNotEmpty(column.ForeignKey) {
@@ -193,34 +459,37 @@ func writeDataDict(data *DataDef, out string) error {
 		return tracerr.Wrap(err)
 	}
 
+	// the primary key is in the table row, the last column marks the fixed columns
+	headings := append(lo.Map(dictColumns, func(dc dictColumn, _ int) string { return dc.heading }), primaryKeyHeading, fixedHeading)
+	widths := append([]float64{2}, append(lo.Map(dictColumns, func(dc dictColumn, _ int) float64 { return dc.width }), 20, 6)...)
+	lastCol := len(headings)
+	titleCol := 1 + lo.IndexOf(headings, "Title")
+	descCol := 1 + lo.IndexOf(headings, "Description")
+	pkCol := 1 + lo.IndexOf(headings, primaryKeyHeading)
+	fkCol := 1 + lo.IndexOf(headings, "Foreign Key")
+	ixCol := 1 + lo.IndexOf(headings, "Index")
+	valuesCol := 1 + lo.IndexOf(headings, "Values")
+
 	for _, schema := range data.Schemas {
 		sheet := schema.Name
 		excel.NewSheet(sheet)
 
 		// heading
-		headings := []string{"Column Name", "Title", "Data Type", "Identity", "Not Null", "Default", "Foreign Key", "Description"}
 		for i, heading := range headings {
-			cell := fmt.Sprintf("%c1", 66+i) // start from column 2
-			excel.SetCellValue(sheet, cell, heading)
+			excel.SetCellValue(sheet, dictCell(i+1, 1), heading) // start from column 2
 		}
 		// styling
-		excel.SetCellStyle(sheet, "A1", fmt.Sprintf("%c1", 65+len(headings)), (*style)["header"])
+		excel.SetCellStyle(sheet, "A1", dictCell(lastCol, 1), (*style)["header"])
 		// column width
-		widths := []float64{2, 20, 20, 15, 8, 8, 10, 25, 50}
 		for i, width := range widths {
-			col := fmt.Sprintf("%c", 65+i)
+			col, _ := excelize.ColumnNumberToName(i + 1)
 			excel.SetColWidth(sheet, col, col, width)
 		}
 
 		var setColValue = func(rowIndex int, column Column) {
-			excel.SetCellValue(sheet, fmt.Sprintf("B%d", rowIndex), column.Name)
-			excel.SetCellValue(sheet, fmt.Sprintf("C%d", rowIndex), column.Title)
-			excel.SetCellValue(sheet, fmt.Sprintf("D%d", rowIndex), column.DataType)
-			excel.SetCellValue(sheet, fmt.Sprintf("E%d", rowIndex), column.Identity)
-			excel.SetCellValue(sheet, fmt.Sprintf("F%d", rowIndex), column.NotNull)
-			excel.SetCellValue(sheet, fmt.Sprintf("G%d", rowIndex), column.Value)
-			excel.SetCellValue(sheet, fmt.Sprintf("H%d", rowIndex), column.ForeignKey)
-			excel.SetCellValue(sheet, fmt.Sprintf("I%d", rowIndex), column.Desc)
+			for i, dc := range dictColumns {
+				excel.SetCellValue(sheet, dictCell(i+1, rowIndex), dc.get(&column))
+			}
 		}
 
 		rowctnr := 2 // row counter
@@ -228,16 +497,29 @@ func writeDataDict(data *DataDef, out string) error {
 		for _, table := range schema.Tables {
 			{
 				// table infomation
-				text := table.Name
+				excel.SetCellValue(sheet, dictCell(0, rowctnr), table.Name)
 				if table.Title != "" {
-					text += " - " + table.Title
+					excel.SetCellValue(sheet, dictCell(titleCol, rowctnr), table.Title)
 				}
 				if table.Desc != "" {
-					text += " - " + table.Desc
+					excel.SetCellValue(sheet, dictCell(descCol, rowctnr), table.Desc)
+				}
+				if len(table.PrimaryKey) > 0 {
+					excel.SetCellValue(sheet, dictCell(pkCol, rowctnr), strings.Join(table.PrimaryKey, ", "))
+				}
+				if len(table.ForeignKeys) > 0 {
+					excel.SetCellValue(sheet, dictCell(fkCol, rowctnr),
+						strings.Join(lo.Map(table.ForeignKeys, func(fk ForeignKey, _ int) string { return formatDictForeignKey(fk) }), "\n"))
+				}
+				if len(table.Indexes) > 0 {
+					excel.SetCellValue(sheet, dictCell(ixCol, rowctnr),
+						strings.Join(lo.Map(table.Indexes, func(ix Index, _ int) string { return formatDictIndex(ix) }), "\n"))
+				}
+				if len(table.Checks) > 0 {
+					excel.SetCellValue(sheet, dictCell(valuesCol, rowctnr),
+						strings.Join(lo.Map(table.Checks, func(c Check, _ int) string { return formatDictCheck(c) }), "\n"))
 				}
-				cell := fmt.Sprintf("A%d", rowctnr)
-				excel.SetCellValue(sheet, cell, text)
-				excel.SetCellStyle(sheet, cell, fmt.Sprintf("%c%d", 65+len(headings), rowctnr), (*style)["table"])
+				excel.SetCellStyle(sheet, dictCell(0, rowctnr), dictCell(lastCol, rowctnr), (*style)["table"])
 				rowctnr += 1
 			}
 
@@ -249,13 +531,15 @@ func writeDataDict(data *DataDef, out string) error {
 			rowctnr += len(table.Columns)
 
 			// fixed columns
-			for i, column := range data.Fixed {
+			fixed := table.FixedColumns(data.Fixed)
+			for i, column := range fixed {
 				index := i + rowctnr
 				setColValue(index, column)
-				excel.SetCellStyle(sheet, fmt.Sprintf("B%d", index), fmt.Sprintf("%c%d", 65+len(headings), index), (*style)["fixcol"])
+				excel.SetCellValue(sheet, dictCell(lastCol, index), "Y")
+				excel.SetCellStyle(sheet, dictCell(1, index), dictCell(lastCol, index), (*style)["fixcol"])
 			}
 
-			rowctnr += len(data.Fixed)
+			rowctnr += len(fixed)
 		}
 	}
 	excel.DeleteSheet("Sheet1")
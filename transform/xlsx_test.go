@@ -0,0 +1,159 @@
+package transform
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// xlsxFixture uses every attribute kept by the data dictionary.
+const xlsxFixture = `fixed:
+  - { na: created, ty: DATETIME, nu: Y, va: CURRENT_TIMESTAMP, dc: "record create time" }
+  - { na: recver, ty: int, nu: Y, va: 0, dc: "record version" }
+
+schemas:
+  - name: General
+    tables:
+      - name: doc
+        title: document
+        desc: External document/files
+        columns:
+          - { na: doc_id, ty: INT, id: Y, nu: Y, tt: ID, dc: "unique identifier" }
+          - { na: ref, ty: VARCHAR(50), nu: Y, un: Y, dc: "reference" }
+          - { na: kind, ty: CHAR(3), in: Y, enum: [COM, PRE], va: COM }
+          - { na: state, ty: CHAR(1), enum: { A: active, D: deleted } }
+        checks:
+          - { expr: "ref <> ''" }
+          - { name: ck_doc_kind_state, expr: "kind <> 'PRE' OR state = 'A'" }
+      - name: tag
+        exclude_fixed: true
+        columns:
+          - { na: code, ty: VARCHAR(10), nu: Y }
+          - { na: lang, ty: CHAR(2), nu: Y }
+          - { na: name, ty: VARCHAR(20) }
+        primary_key: [code, lang]
+        indexes:
+          - { columns: [name] }
+          - { name: ux_tag_name, columns: [lang, name DESC], unique: true, where: "name IS NOT NULL", include: [code] }
+      - name: doc_tag
+        desc: "Relationship table between doc and tag"
+        columns:
+          - { na: doc_id, ty: INT, nu: Y, fk: doc.doc_id, cd: "0..*:1", in: Y }
+          - { na: tag_code, ty: VARCHAR(10), nu: Y }
+          - { na: tag_lang, ty: CHAR(2), nu: Y }
+        primary_key: [doc_id, tag_code, tag_lang]
+        foreign_keys:
+          - { name: fk_doc_tag_tag, columns: [tag_code, tag_lang], ref_table: tag, ref_columns: [code, lang], on_delete: CASCADE, on_update: SET NULL }
+          - { columns: [doc_id], ref_table: doc }
+  - name: Audit
+    tables:
+      - name: log
+        columns:
+          - { na: log_id, ty: BIGINT, id: Y, nu: Y }
+          - { na: doc_id, ty: INT, fk: doc.doc_id }
+`
+
+// clearPositions clears the positions of the definition, which differ
+// between the yaml and xlsx files.
+func clearPositions(data *DataDef) {
+	var clear func(v reflect.Value)
+	clear = func(v reflect.Value) {
+		switch v.Kind() {
+		case reflect.Ptr:
+			if !v.IsNil() {
+				clear(v.Elem())
+			}
+		case reflect.Struct:
+			if v.Type() == reflect.TypeOf(Position{}) {
+				v.Set(reflect.ValueOf(Position{}))
+				return
+			}
+			for i := 0; i < v.NumField(); i++ {
+				clear(v.Field(i))
+			}
+		case reflect.Slice:
+			for i := 0; i < v.Len(); i++ {
+				clear(v.Index(i))
+			}
+		}
+	}
+	clear(reflect.ValueOf(data))
+}
+
+func TestXlsxRoundTrip(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "s.xlsx")
+	if err := WriteXlsx(readTestYml(t, xlsxFixture), file, false); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadXlsx(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := readTestYml(t, xlsxFixture)
+	clearPositions(got)
+	clearPositions(want)
+	if !reflect.DeepEqual(got, want) {
+		gotYml, _ := yaml.Marshal(got)
+		wantYml, _ := yaml.Marshal(want)
+		t.Errorf("round trip differs\ngot:\n%s\nwant:\n%s", gotYml, wantYml)
+	}
+}
+
+func TestXlsxRoundTripPositions(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "s.xlsx")
+	if err := WriteXlsx(readTestYml(t, xlsxFixture), file, false); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ReadXlsx(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	table := data.Schemas[0].Tables[0]
+	if table.Pos.File != file || table.Pos.Line == 0 {
+		t.Errorf("table position = %v", table.Pos)
+	}
+	if column := table.Columns[0]; column.Pos.Line <= table.Pos.Line {
+		t.Errorf("column position = %v, table position = %v", column.Pos, table.Pos)
+	}
+}
+
+func TestDictEnum(t *testing.T) {
+	tests := []struct {
+		enum Enum
+		text string
+	}{
+		{Enum{{Value: "COM"}, {Value: "PRE"}}, "COM\nPRE"},
+		{Enum{{Value: "A", Label: "active"}, {Value: "D", Label: "deleted: gone"}}, "A: active\nD: deleted: gone"},
+		{Enum{{Value: "A", Label: "active"}, {Value: "X"}}, "A: active\nX"},
+	}
+	for _, tt := range tests {
+		if got := formatDictEnum(tt.enum); got != tt.text {
+			t.Errorf("formatDictEnum(%v) = %q, want %q", tt.enum, got, tt.text)
+		}
+		if got := parseDictEnum(tt.text + "\n\n"); !reflect.DeepEqual(got, tt.enum) {
+			t.Errorf("parseDictEnum(%q) = %v, want %v", tt.text, got, tt.enum)
+		}
+	}
+}
+
+func TestDictCheck(t *testing.T) {
+	tests := []struct {
+		check Check
+		text  string
+	}{
+		{Check{Expr: "ver >= 0"}, "ver >= 0"},
+		{Check{Name: "ck_doc_ver", Expr: "ver >= 0"}, "ck_doc_ver: ver >= 0"},
+		// the colon of the expression is not the name
+		{Check{Expr: "created::date > '2020-01-01'"}, "created::date > '2020-01-01'"},
+	}
+	for _, tt := range tests {
+		if got := formatDictCheck(tt.check); got != tt.text {
+			t.Errorf("formatDictCheck(%v) = %q, want %q", tt.check, got, tt.text)
+		}
+		if got := parseDictCheck(" " + tt.text + " "); got != tt.check {
+			t.Errorf("parseDictCheck(%q) = %v, want %v", tt.text, got, tt.check)
+		}
+	}
+}
@@ -4,23 +4,204 @@ import (
 	"fmt"
 	"io/fs"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/samber/lo"
 	"github.com/ztrue/tracerr"
 	"gopkg.in/yaml.v3"
 )
 
+func init() {
+	Register(Format{
+		Name:    "yaml",
+		Aliases: []string{"y", "yml"},
+		Usage:   "definition file in yaml",
+		Exts:    []string{".yml", ".yaml"},
+		Reader:  ReaderFunc(func(file string, _ Options) (*DataDef, error) { return ReadYml(file) }),
+		Writer:  WriterFunc(func(data *DataDef, out string, _ Options) error { return WriteYml(data, out) }),
+	})
+}
+
+// ReadYml reads the definition from the yaml file, or all yaml files in the
+// directory (the hidden files are skipped, e.g. .dst.yml). The files in the
+// include list are read relative to the including file, all files are merged
+// into one definition, see mergeData.
 func ReadYml(file string) (*DataDef, error) {
-	yamlFile, err := os.ReadFile(file)
+	data := &DataDef{}
+	if err := readYml(data, file, make(map[string]bool)); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// readYml reads the file or directory and merges it into the data, the
+// visited files are read once only.
+func readYml(data *DataDef, file string, visited map[string]bool) error {
+	info, err := os.Stat(file)
 	if err != nil {
-		return nil, tracerr.Wrap(err)
+		return tracerr.Wrap(err)
+	}
+	if info.IsDir() {
+		files, err := ymlFiles(file)
+		if err != nil {
+			return err
+		}
+		for _, f := range files {
+			if err := readYml(data, f, visited); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return tracerr.Wrap(err)
 	}
+	if visited[abs] {
+		return nil
+	}
+	visited[abs] = true
 
+	yamlFile, err := os.ReadFile(file)
+	if err != nil {
+		return tracerr.Wrap(err)
+	}
 	var d DataDef
 	if err := yaml.Unmarshal(yamlFile, &d); err != nil {
+		return tracerr.Errorf("%s: %v", file, err)
+	}
+	setSourceFile(&d, file)
+	if err := mergeData(data, &d); err != nil {
+		return err
+	}
+
+	for _, include := range d.Include {
+		pattern := include
+		if !filepath.IsAbs(pattern) {
+			pattern = filepath.Join(filepath.Dir(file), pattern)
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return tracerr.Errorf("%s: invalid include '%s': %v", file, include, err)
+		}
+		if len(matches) == 0 {
+			return tracerr.Errorf("%s: include '%s' cannot be found", file, include)
+		}
+		for _, match := range matches {
+			if err := readYml(data, match, visited); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ymlFiles returns the yaml files in the directory and its sub-directories in
+// lexical order, the hidden files and directories are skipped.
+func ymlFiles(dir string) ([]string, error) {
+	files := make([]string, 0)
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != dir && strings.HasPrefix(entry.Name(), ".") {
+			if entry.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := strings.ToLower(filepath.Ext(path))
+		if !entry.IsDir() && (ext == ".yml" || ext == ".yaml") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, tracerr.Wrap(err)
 	}
-	return &d, err
+	return files, nil
+}
+
+// mergeData merges the definition of a file into the data. The schemas of
+// the same name are merged, the tables and mixins are appended (the
+// duplicates are reported by Verify), a domain or a fixed column cannot be
+// defined more than once.
+func mergeData(data *DataDef, d *DataDef) error {
+	for name, domain := range d.Domains {
+		if found, exists := data.Domains[name]; exists {
+			return tracerr.Errorf("%s: domain '%s' is already defined in %s", domain.Pos.String(), name, found.Pos.String())
+		}
+		if data.Domains == nil {
+			data.Domains = make(map[string]Column)
+		}
+		data.Domains[name] = domain
+	}
+	data.Mixins = append(data.Mixins, d.Mixins...)
+	for _, column := range d.Fixed {
+		if found, exists := lo.Find(data.Fixed, func(c Column) bool { return c.Name == column.Name }); exists {
+			return tracerr.Errorf("%s: fixed column '%s' is already defined in %s", column.Pos.String(), column.Name, found.Pos.String())
+		}
+		data.Fixed = append(data.Fixed, column)
+	}
+	for _, schema := range d.Schemas {
+		i := lo.IndexOf(lo.Map(data.Schemas, func(s Schema, _ int) string { return s.Name }), schema.Name)
+		if i < 0 {
+			data.Schemas = append(data.Schemas, schema)
+			continue
+		}
+		merged := &data.Schemas[i]
+		if merged.Desc == "" {
+			merged.Desc = schema.Desc
+		}
+		merged.Tables = append(merged.Tables, schema.Tables...)
+	}
+	return nil
+}
+
+// setSourceFile sets the file name to the positions of all elements.
+func setSourceFile(data *DataDef, file string) {
+	mapSourceFiles(data, func(string) string { return file })
+}
+
+// mapSourceFiles replaces the file names of the positions of all elements by
+// the mapping.
+func mapSourceFiles(data *DataDef, mapping func(file string) string) {
+	for i := range data.Fixed {
+		data.Fixed[i].Pos.File = mapping(data.Fixed[i].Pos.File)
+	}
+	for name, domain := range data.Domains {
+		domain.Pos.File = mapping(domain.Pos.File)
+		data.Domains[name] = domain
+	}
+	for i := range data.Mixins {
+		mixin := &data.Mixins[i]
+		mixin.Pos.File = mapping(mixin.Pos.File)
+		for j := range mixin.Columns {
+			mixin.Columns[j].Pos.File = mapping(mixin.Columns[j].Pos.File)
+		}
+	}
+	for i := range data.Schemas {
+		schema := &data.Schemas[i]
+		schema.Pos.File = mapping(schema.Pos.File)
+		for j := range schema.Tables {
+			table := &schema.Tables[j]
+			table.Pos.File = mapping(table.Pos.File)
+			for k := range table.Columns {
+				table.Columns[k].Pos.File = mapping(table.Columns[k].Pos.File)
+			}
+			for k := range table.ForeignKeys {
+				table.ForeignKeys[k].Pos.File = mapping(table.ForeignKeys[k].Pos.File)
+			}
+			for k := range table.Indexes {
+				table.Indexes[k].Pos.File = mapping(table.Indexes[k].Pos.File)
+			}
+			for k := range table.Checks {
+				table.Checks[k].Pos.File = mapping(table.Checks[k].Pos.File)
+			}
+		}
+	}
 }
 
 func WriteYml(data *DataDef, outfile string) error {
@@ -64,7 +245,9 @@ func WriteYml(data *DataDef, outfile string) error {
 	if outfile == "" || outfile == "stdout" {
 		fmt.Println(output)
 	} else {
-		os.WriteFile(outfile, []byte(output), fs.FileMode(0744))
+		if err := os.WriteFile(outfile, []byte(output), fs.FileMode(0744)); err != nil {
+			return tracerr.Wrap(err)
+		}
 	}
 	return nil
 }
@@ -0,0 +1,74 @@
+package transform
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/samber/lo"
+)
+
+func TestReadYmlFiles(t *testing.T) {
+	doc := "schemas:\n  - name: app\n    tables:\n      - name: doc\n        columns:\n          - { na: doc_id, ty: INT, id: Y, nu: Y }\n"
+	tag := "schemas:\n  - name: app\n    tables:\n      - name: tag\n        columns:\n          - { na: tag_id, ty: INT, id: Y, nu: Y }\n" +
+		"          - { na: doc_id, ty: INT, fk: doc.doc_id }\n"
+	tests := []struct {
+		name     string
+		files    map[string]string
+		read     string   // the file or directory read
+		tables   []string // the tables in order
+		findings []string // the messages of the findings
+		err      string   // the error message
+	}{
+		{"directory", map[string]string{"b/tag.yml": tag, "a.yaml": doc, ".hidden.yml": "schemas: [{name: x}]\n", "note.txt": "x"},
+			".", []string{"doc", "tag"}, nil, ""},
+		{"include patterns", map[string]string{"s.yml": "include: [parts/*.yml]\n", "parts/doc.yml": doc, "parts/tag.yml": tag},
+			"s.yml", []string{"doc", "tag"}, nil, ""},
+		// the foreign key of tag refers to doc of the including file
+		{"cross-file foreign key", map[string]string{"tag.yml": "include: [doc.yml]\n" + tag, "doc.yml": doc},
+			"tag.yml", []string{"tag", "doc"}, nil, ""},
+		{"include cycle", map[string]string{"doc.yml": "include: [tag.yml]\n" + doc, "tag.yml": "include: [doc.yml]\n" + tag},
+			"doc.yml", []string{"doc", "tag"}, nil, ""},
+		{"missing foreign table", map[string]string{"tag.yml": tag},
+			"tag.yml", []string{"tag"}, []string{"[FK: doc.doc_id] cannot be found"}, ""},
+		{"duplicate table", map[string]string{"a.yml": doc, "b.yml": doc},
+			".", []string{"doc", "doc"}, []string{"duplicate table 'doc', first defined at a.yml:4:9"}, ""},
+		{"duplicate domain", map[string]string{"a.yml": "domains:\n  money: { ty: INT }\n", "b.yml": "domains:\n  money: { ty: INT }\n"},
+			".", nil, nil, "b.yml:2:10: domain 'money' is already defined in a.yml:2:10"},
+		{"duplicate fixed column", map[string]string{"a.yml": "fixed:\n  - { na: created, ty: DATETIME }\n", "b.yml": "fixed:\n  - { na: created, ty: DATETIME }\n"},
+			".", nil, nil, "b.yml:2:5: fixed column 'created' is already defined in a.yml:2:5"},
+		{"missing include", map[string]string{"s.yml": "include: [none.yml]\n"},
+			"s.yml", nil, nil, "include 'none.yml' cannot be found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, content := range tt.files {
+				writeTestFile(t, filepath.Join(dir, name), content)
+			}
+			// the positions are relative to the directory
+			data, err := ReadYml(filepath.Join(dir, tt.read))
+			if tt.err != "" {
+				if err == nil || !strings.Contains(strings.ReplaceAll(err.Error(), dir+string(filepath.Separator), ""), tt.err) {
+					t.Fatalf("error = %v, want %s", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := lo.FlatMap(data.Schemas, func(s Schema, _ int) []string {
+				return lo.Map(s.Tables, func(table Table, _ int) string { return table.Name })
+			})
+			if strings.Join(got, ",") != strings.Join(tt.tables, ",") {
+				t.Errorf("tables = %v, want %v", got, tt.tables)
+			}
+			findings := lo.Map(VerifyStructure(data), func(f Finding, _ int) string {
+				return strings.ReplaceAll(f.Message, dir+string(filepath.Separator), "")
+			})
+			if strings.Join(findings, "\n") != strings.Join(tt.findings, "\n") {
+				t.Errorf("findings =\n%s\nwant\n%s", strings.Join(findings, "\n"), strings.Join(tt.findings, "\n"))
+			}
+		})
+	}
+}